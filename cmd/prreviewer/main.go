@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
 	"os/signal"
@@ -95,6 +96,9 @@ func bootstrap(
 	openDB func(driverName, dsn string) (*sql.DB, error),
 	logger *zap.SugaredLogger,
 ) (http.Handler, func(), error) {
+	if cfg.ReviewerStrategy != "" && !storage.IsKnownStrategy(cfg.ReviewerStrategy) {
+		return nil, func() {}, fmt.Errorf("unknown reviewer strategy %q", cfg.ReviewerStrategy)
+	}
 	db, err := openDB("pgx", cfg.DatabaseURL)
 	if err != nil {
 		return nil, func() {}, err
@@ -109,7 +113,11 @@ func bootstrap(
 		return nil, func() {}, err
 	}
 
-	store := newStore(db, logger)
+	opts := []storage.Option{storage.WithDefaultStrategy(cfg.ReviewerStrategy)}
+	if cfg.SelectionSeed != nil {
+		opts = append(opts, storage.WithSeed(*cfg.SelectionSeed))
+	}
+	store := newStore(db, logger, opts...)
 	svc := service.New(store)
 	handler := api.NewServer(svc, logger).Routes()
 
@@ -25,25 +25,107 @@ func (fakeStore) AddTeam(context.Context, storage.TeamPayload) (storage.TeamPayl
 func (fakeStore) GetTeam(context.Context, string) (storage.TeamPayload, error) {
 	return storage.TeamPayload{TeamName: "team"}, nil
 }
-func (fakeStore) SetUserActive(context.Context, storage.SetActivePayload) (*storage.User, error) {
-	return &storage.User{ID: "u1"}, nil
+func (fakeStore) ListTeams(context.Context) ([]storage.TeamSummary, error) {
+	return []storage.TeamSummary{{TeamName: "team"}}, nil
+}
+func (fakeStore) AddTeamMembers(context.Context, storage.MembersPayload) (storage.TeamPayload, error) {
+	return storage.TeamPayload{TeamName: "team"}, nil
+}
+func (fakeStore) RemoveTeamMembers(context.Context, storage.RemoveMembersPayload) (*storage.RemovedMembers, error) {
+	return &storage.RemovedMembers{Team: storage.TeamPayload{TeamName: "team"}}, nil
+}
+func (fakeStore) RenameTeam(context.Context, storage.RenameTeamPayload) (storage.TeamPayload, error) {
+	return storage.TeamPayload{TeamName: "team"}, nil
+}
+func (fakeStore) DeleteTeam(context.Context, string) (*storage.DeletedTeam, error) {
+	return &storage.DeletedTeam{TeamName: "team"}, nil
+}
+func (fakeStore) SetUserActive(context.Context, storage.SetActivePayload) (*storage.User, *storage.ReassignmentSummary, error) {
+	return &storage.User{ID: "u1"}, nil, nil
 }
 func (fakeStore) CreatePR(context.Context, storage.CreatePRPayload) (*storage.PullRequest, error) {
 	return &storage.PullRequest{ID: "pr1"}, nil
 }
+func (fakeStore) PreviewPR(context.Context, storage.CreatePRPayload) (*storage.AssignmentPreview, error) {
+	return &storage.AssignmentPreview{AuthorID: "u1"}, nil
+}
 func (fakeStore) MergePR(context.Context, string) (*storage.PullRequest, error) {
 	return &storage.PullRequest{ID: "pr1", Status: storage.StatusMerged}, nil
 }
+func (fakeStore) SubmitReview(context.Context, storage.SubmitReviewPayload) (*storage.PullRequest, error) {
+	return &storage.PullRequest{ID: "pr1"}, nil
+}
+func (fakeStore) GetPR(context.Context, string) (*storage.PullRequest, error) {
+	return &storage.PullRequest{ID: "pr1", Status: storage.StatusOpen}, nil
+}
+func (fakeStore) ListPRs(context.Context, storage.PRFilter) (*storage.PRPage, error) {
+	return &storage.PRPage{PullRequests: []storage.PullRequest{}}, nil
+}
+func (fakeStore) ClosePR(context.Context, string) (*storage.PullRequest, error) {
+	return &storage.PullRequest{ID: "pr1", Status: storage.StatusClosed}, nil
+}
+func (fakeStore) ReadyPR(context.Context, storage.CreatePRPayload) (*storage.PullRequest, error) {
+	return &storage.PullRequest{ID: "pr1", Status: storage.StatusOpen}, nil
+}
+func (fakeStore) ReopenPR(context.Context, storage.CreatePRPayload) (*storage.PullRequest, error) {
+	return &storage.PullRequest{ID: "pr1", Status: storage.StatusOpen}, nil
+}
 func (fakeStore) Reassign(context.Context, storage.ReassignPayload) (*storage.PullRequest, string, error) {
 	return &storage.PullRequest{ID: "pr1"}, "u2", nil
 }
-func (fakeStore) UserReviews(context.Context, string) ([]storage.PullRequestShort, error) {
-	return []storage.PullRequestShort{}, nil
+func (fakeStore) AddReviewer(context.Context, storage.ReviewerPayload) (*storage.PullRequest, error) {
+	return &storage.PullRequest{ID: "pr1"}, nil
+}
+func (fakeStore) RemoveReviewer(context.Context, storage.ReviewerPayload) (*storage.PullRequest, error) {
+	return &storage.PullRequest{ID: "pr1"}, nil
+}
+func (fakeStore) UserReviews(context.Context, string, storage.PRFilter) (*storage.PRPage, error) {
+	return &storage.PRPage{PullRequests: []storage.PullRequest{}}, nil
 }
 func (fakeStore) Stats(context.Context) (*storage.Stats, error) {
 	return &storage.Stats{}, nil
 }
 func (fakeStore) MassDeactivate(context.Context, string) error { return nil }
+func (fakeStore) UpdateTeamSettings(context.Context, storage.TeamSettingsPayload) (*storage.TeamSettings, error) {
+	return &storage.TeamSettings{}, nil
+}
+func (fakeStore) SetOwnership(context.Context, storage.OwnershipPayload) (*storage.Ownership, error) {
+	return &storage.Ownership{}, nil
+}
+func (fakeStore) TeamOwnership(context.Context, string) (*storage.Ownership, error) {
+	return &storage.Ownership{}, nil
+}
+func (fakeStore) PRHistory(context.Context, string) ([]storage.AssignmentEvent, error) {
+	return []storage.AssignmentEvent{}, nil
+}
+func (fakeStore) SetMaxOpenReviews(context.Context, storage.SetMaxOpenReviewsPayload) (*storage.User, error) {
+	return &storage.User{ID: "u1"}, nil
+}
+func (fakeStore) SetUserRole(context.Context, storage.SetRolePayload) (*storage.User, error) {
+	return &storage.User{ID: "u1"}, nil
+}
+func (fakeStore) GetUser(context.Context, string) (*storage.User, error) {
+	return &storage.User{ID: "u1"}, nil
+}
+func (fakeStore) UpdateUser(context.Context, storage.UpdateUserPayload) (*storage.User, error) {
+	return &storage.User{ID: "u1"}, nil
+}
+func (fakeStore) MoveUserTeam(context.Context, storage.MoveTeamPayload) (*storage.User, *storage.ReassignmentSummary, error) {
+	return &storage.User{ID: "u1"}, nil, nil
+}
+func (fakeStore) DeleteUser(context.Context, string) (*storage.DeletedUser, error) {
+	return &storage.DeletedUser{UserID: "u1"}, nil
+}
+func (fakeStore) AddAbsence(context.Context, storage.AbsencePayload) (*storage.Absence, error) {
+	return &storage.Absence{}, nil
+}
+func (fakeStore) UserAbsences(context.Context, string) ([]storage.Absence, error) {
+	return []storage.Absence{}, nil
+}
+func (fakeStore) UpdateAbsence(context.Context, storage.AbsenceUpdatePayload) (*storage.Absence, error) {
+	return &storage.Absence{}, nil
+}
+func (fakeStore) RemoveAbsence(context.Context, int64) error { return nil }
 
 // smoke test: server starts and stops on context cancel
 func TestRunStartsAndStops(t *testing.T) {
@@ -135,3 +217,15 @@ func TestBootstrapOpenError(t *testing.T) {
 		t.Fatal("expected open error")
 	}
 }
+
+func TestBootstrapUnknownStrategy(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	open := func(driver, dsn string) (*sql.DB, error) {
+		t.Fatal("db must not be opened with invalid config")
+		return nil, nil
+	}
+	cfg := &configs.Config{DatabaseURL: "custom", HTTPAddr: ":0", ReviewerStrategy: "fastest"}
+	if _, _, err := bootstrap(cfg, open, logger); err == nil {
+		t.Fatal("expected unknown strategy error")
+	}
+}
@@ -3,17 +3,25 @@ package configs
 import (
 	"fmt"
 	"os"
+	"strconv"
 )
 
 type Config struct {
-	DatabaseURL string
-	HTTPAddr    string
+	DatabaseURL      string
+	HTTPAddr         string
+	ReviewerStrategy string
+	// SelectionSeed fixes the reviewer selection randomness; nil seeds from the clock.
+	SelectionSeed *int64
 }
 
-const defaultHTTPAddr = ":8080"
+const (
+	defaultHTTPAddr         = ":8080"
+	defaultReviewerStrategy = "random"
+)
 
 // Load reads configuration from environment variables.
 // Required: DATABASE_URL, HTTP_ADDR (defaults to :8080 if empty).
+// Optional: REVIEWER_STRATEGY (defaults to random), SELECTION_SEED (an int64).
 func Load() (*Config, error) {
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
@@ -23,8 +31,22 @@ func Load() (*Config, error) {
 	if addr == "" {
 		addr = defaultHTTPAddr
 	}
+	strategy := os.Getenv("REVIEWER_STRATEGY")
+	if strategy == "" {
+		strategy = defaultReviewerStrategy
+	}
+	var seed *int64
+	if raw := os.Getenv("SELECTION_SEED"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SELECTION_SEED: %w", err)
+		}
+		seed = &v
+	}
 	return &Config{
-		DatabaseURL: dbURL,
-		HTTPAddr:    addr,
+		DatabaseURL:      dbURL,
+		HTTPAddr:         addr,
+		ReviewerStrategy: strategy,
+		SelectionSeed:    seed,
 	}, nil
 }
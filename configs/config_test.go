@@ -27,6 +27,38 @@ func TestLoadDefaultAddr(t *testing.T) {
 	if cfg.HTTPAddr != defaultHTTPAddr {
 		t.Fatalf("expected %s, got %s", defaultHTTPAddr, cfg.HTTPAddr)
 	}
+	if cfg.ReviewerStrategy != defaultReviewerStrategy {
+		t.Fatalf("expected %s, got %s", defaultReviewerStrategy, cfg.ReviewerStrategy)
+	}
+}
+
+func TestLoadReviewerStrategy(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://example")
+	t.Setenv("REVIEWER_STRATEGY", "round_robin")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.ReviewerStrategy != "round_robin" {
+		t.Fatalf("unexpected strategy: %s", cfg.ReviewerStrategy)
+	}
+}
+
+func TestLoadSelectionSeed(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://example")
+	t.Setenv("SELECTION_SEED", "42")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if cfg.SelectionSeed == nil || *cfg.SelectionSeed != 42 {
+		t.Fatalf("unexpected seed: %v", cfg.SelectionSeed)
+	}
+
+	t.Setenv("SELECTION_SEED", "abc")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for invalid SELECTION_SEED")
+	}
 }
 
 func TestLoadMissingDBURL(t *testing.T) {
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+
+	"prreviewer/internal/storage"
+)
+
+func (s *server) handleAddAbsence(w http.ResponseWriter, r *http.Request) {
+	var payload storage.AbsencePayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.UserID == "" || payload.StartsAt.IsZero() || payload.EndsAt.IsZero() {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id, starts_at and ends_at are required", s.logger)
+		return
+	}
+	absence, err := s.svc.AddAbsence(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"absence": absence}, s.logger)
+}
+
+func (s *server) handleGetAbsences(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id is required", s.logger)
+		return
+	}
+	absences, err := s.svc.UserAbsences(r.Context(), userID)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"user_id":  userID,
+		"absences": absences,
+	}, s.logger)
+}
+
+func (s *server) handleUpdateAbsence(w http.ResponseWriter, r *http.Request) {
+	var payload storage.AbsenceUpdatePayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.ID <= 0 {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "absence_id is required", s.logger)
+		return
+	}
+	absence, err := s.svc.UpdateAbsence(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"absence": absence}, s.logger)
+}
+
+func (s *server) handleRemoveAbsence(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		ID int64 `json:"absence_id"`
+	}
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.ID <= 0 {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "absence_id is required", s.logger)
+		return
+	}
+	if err := s.svc.RemoveAbsence(r.Context(), payload.ID); err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"absence_id": payload.ID, "status": "removed"}, s.logger)
+}
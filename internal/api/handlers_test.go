@@ -5,9 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"prreviewer/internal/service"
 	"prreviewer/internal/storage"
@@ -17,14 +21,41 @@ import (
 
 type stubStore struct {
 	createPR    func(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error)
+	preview     func(ctx context.Context, payload storage.CreatePRPayload) (*storage.AssignmentPreview, error)
 	reassign    func(ctx context.Context, payload storage.ReassignPayload) (*storage.PullRequest, string, error)
-	userReviews func(ctx context.Context, userID string) ([]storage.PullRequestShort, error)
+	addReviewer func(ctx context.Context, payload storage.ReviewerPayload) (*storage.PullRequest, error)
+	rmReviewer  func(ctx context.Context, payload storage.ReviewerPayload) (*storage.PullRequest, error)
+	userReviews func(ctx context.Context, userID string, filter storage.PRFilter) (*storage.PRPage, error)
 	stats       func(ctx context.Context) (*storage.Stats, error)
 	addTeam     func(ctx context.Context, payload storage.TeamPayload) (storage.TeamPayload, error)
 	getTeam     func(ctx context.Context, teamName string) (storage.TeamPayload, error)
-	setIsActive func(ctx context.Context, payload storage.SetActivePayload) (*storage.User, error)
+	listTeams   func(ctx context.Context) ([]storage.TeamSummary, error)
+	addMembers  func(ctx context.Context, payload storage.MembersPayload) (storage.TeamPayload, error)
+	rmMembers   func(ctx context.Context, payload storage.RemoveMembersPayload) (*storage.RemovedMembers, error)
+	renameTeam  func(ctx context.Context, payload storage.RenameTeamPayload) (storage.TeamPayload, error)
+	deleteTeam  func(ctx context.Context, teamName string) (*storage.DeletedTeam, error)
+	setIsActive func(ctx context.Context, payload storage.SetActivePayload) (*storage.User, *storage.ReassignmentSummary, error)
 	merge       func(ctx context.Context, id string) (*storage.PullRequest, error)
+	closePR     func(ctx context.Context, id string) (*storage.PullRequest, error)
+	getPR       func(ctx context.Context, id string) (*storage.PullRequest, error)
+	listPRs     func(ctx context.Context, filter storage.PRFilter) (*storage.PRPage, error)
+	review      func(ctx context.Context, payload storage.SubmitReviewPayload) (*storage.PullRequest, error)
+	openPR      func(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error)
 	deactivate  func(ctx context.Context, team string) error
+	settings    func(ctx context.Context, payload storage.TeamSettingsPayload) (*storage.TeamSettings, error)
+	setOwners   func(ctx context.Context, payload storage.OwnershipPayload) (*storage.Ownership, error)
+	owners      func(ctx context.Context, teamName string) (*storage.Ownership, error)
+	history     func(ctx context.Context, prID string) ([]storage.AssignmentEvent, error)
+	setCapacity func(ctx context.Context, payload storage.SetMaxOpenReviewsPayload) (*storage.User, error)
+	setRole     func(ctx context.Context, payload storage.SetRolePayload) (*storage.User, error)
+	getUser     func(ctx context.Context, userID string) (*storage.User, error)
+	updateUser  func(ctx context.Context, payload storage.UpdateUserPayload) (*storage.User, error)
+	moveTeam    func(ctx context.Context, payload storage.MoveTeamPayload) (*storage.User, *storage.ReassignmentSummary, error)
+	deleteUser  func(ctx context.Context, userID string) (*storage.DeletedUser, error)
+	addAbsence  func(ctx context.Context, payload storage.AbsencePayload) (*storage.Absence, error)
+	absences    func(ctx context.Context, userID string) ([]storage.Absence, error)
+	updAbsence  func(ctx context.Context, payload storage.AbsenceUpdatePayload) (*storage.Absence, error)
+	rmAbsence   func(ctx context.Context, id int64) error
 }
 
 func (s *stubStore) AddTeam(_ context.Context, payload storage.TeamPayload) (storage.TeamPayload, error) {
@@ -41,11 +72,52 @@ func (s *stubStore) GetTeam(_ context.Context, teamName string) (storage.TeamPay
 	return storage.TeamPayload{TeamName: teamName}, nil
 }
 
-func (s *stubStore) SetUserActive(_ context.Context, payload storage.SetActivePayload) (*storage.User, error) {
+func (s *stubStore) ListTeams(ctx context.Context) ([]storage.TeamSummary, error) {
+	if s.listTeams != nil {
+		return s.listTeams(ctx)
+	}
+	return []storage.TeamSummary{}, nil
+}
+
+func (s *stubStore) AddTeamMembers(ctx context.Context, payload storage.MembersPayload) (storage.TeamPayload, error) {
+	if s.addMembers != nil {
+		return s.addMembers(ctx, payload)
+	}
+	return storage.TeamPayload{TeamName: payload.TeamName, Members: payload.Members}, nil
+}
+
+func (s *stubStore) RemoveTeamMembers(
+	ctx context.Context,
+	payload storage.RemoveMembersPayload,
+) (*storage.RemovedMembers, error) {
+	if s.rmMembers != nil {
+		return s.rmMembers(ctx, payload)
+	}
+	return &storage.RemovedMembers{Team: storage.TeamPayload{TeamName: payload.TeamName}}, nil
+}
+
+func (s *stubStore) RenameTeam(ctx context.Context, payload storage.RenameTeamPayload) (storage.TeamPayload, error) {
+	if s.renameTeam != nil {
+		return s.renameTeam(ctx, payload)
+	}
+	return storage.TeamPayload{TeamName: payload.NewName}, nil
+}
+
+func (s *stubStore) DeleteTeam(ctx context.Context, teamName string) (*storage.DeletedTeam, error) {
+	if s.deleteTeam != nil {
+		return s.deleteTeam(ctx, teamName)
+	}
+	return &storage.DeletedTeam{TeamName: teamName, OwnershipRules: []storage.OwnedRule{}}, nil
+}
+
+func (s *stubStore) SetUserActive(
+	_ context.Context,
+	payload storage.SetActivePayload,
+) (*storage.User, *storage.ReassignmentSummary, error) {
 	if s.setIsActive != nil {
 		return s.setIsActive(context.Background(), payload)
 	}
-	return &storage.User{ID: payload.UserID, IsActive: payload.IsActive}, nil
+	return &storage.User{ID: payload.UserID, IsActive: payload.IsActive}, nil, nil
 }
 
 func (s *stubStore) CreatePR(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error) {
@@ -55,6 +127,16 @@ func (s *stubStore) CreatePR(ctx context.Context, payload storage.CreatePRPayloa
 	return &storage.PullRequest{ID: payload.ID, AuthorID: payload.Author}, nil
 }
 
+func (s *stubStore) PreviewPR(
+	ctx context.Context,
+	payload storage.CreatePRPayload,
+) (*storage.AssignmentPreview, error) {
+	if s.preview != nil {
+		return s.preview(ctx, payload)
+	}
+	return &storage.AssignmentPreview{AuthorID: payload.Author, AssignedReviewers: []string{"u2"}}, nil
+}
+
 func (s *stubStore) MergePR(_ context.Context, id string) (*storage.PullRequest, error) {
 	if s.merge != nil {
 		return s.merge(context.Background(), id)
@@ -62,6 +144,53 @@ func (s *stubStore) MergePR(_ context.Context, id string) (*storage.PullRequest,
 	return &storage.PullRequest{ID: id, Status: storage.StatusMerged}, nil
 }
 
+func (s *stubStore) SubmitReview(ctx context.Context, payload storage.SubmitReviewPayload) (*storage.PullRequest, error) {
+	if s.review != nil {
+		return s.review(ctx, payload)
+	}
+	return &storage.PullRequest{
+		ID:                payload.PRID,
+		Status:            storage.StatusOpen,
+		AssignedReviewers: []string{payload.UserID},
+		Reviews:           []storage.Review{{UserID: payload.UserID, Verdict: payload.Verdict}},
+	}, nil
+}
+
+func (s *stubStore) GetPR(ctx context.Context, id string) (*storage.PullRequest, error) {
+	if s.getPR != nil {
+		return s.getPR(ctx, id)
+	}
+	return &storage.PullRequest{ID: id, Status: storage.StatusOpen, AssignedReviewers: []string{"u2"}}, nil
+}
+
+func (s *stubStore) ListPRs(ctx context.Context, filter storage.PRFilter) (*storage.PRPage, error) {
+	if s.listPRs != nil {
+		return s.listPRs(ctx, filter)
+	}
+	return &storage.PRPage{PullRequests: []storage.PullRequest{}}, nil
+}
+
+func (s *stubStore) ClosePR(ctx context.Context, id string) (*storage.PullRequest, error) {
+	if s.closePR != nil {
+		return s.closePR(ctx, id)
+	}
+	return &storage.PullRequest{ID: id, Status: storage.StatusClosed, AssignedReviewers: []string{}}, nil
+}
+
+func (s *stubStore) ReadyPR(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error) {
+	if s.openPR != nil {
+		return s.openPR(ctx, payload)
+	}
+	return &storage.PullRequest{ID: payload.ID, Status: storage.StatusOpen, AssignedReviewers: []string{"u2"}}, nil
+}
+
+func (s *stubStore) ReopenPR(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error) {
+	if s.openPR != nil {
+		return s.openPR(ctx, payload)
+	}
+	return &storage.PullRequest{ID: payload.ID, Status: storage.StatusOpen, AssignedReviewers: []string{"u2"}}, nil
+}
+
 func (s *stubStore) Reassign(ctx context.Context, payload storage.ReassignPayload) (*storage.PullRequest, string, error) {
 	if s.reassign != nil {
 		return s.reassign(ctx, payload)
@@ -69,11 +198,25 @@ func (s *stubStore) Reassign(ctx context.Context, payload storage.ReassignPayloa
 	return &storage.PullRequest{ID: payload.PRID}, "u2", nil
 }
 
-func (s *stubStore) UserReviews(_ context.Context, userID string) ([]storage.PullRequestShort, error) {
+func (s *stubStore) AddReviewer(ctx context.Context, payload storage.ReviewerPayload) (*storage.PullRequest, error) {
+	if s.addReviewer != nil {
+		return s.addReviewer(ctx, payload)
+	}
+	return &storage.PullRequest{ID: payload.PRID, AssignedReviewers: []string{"u2", payload.UserID}}, nil
+}
+
+func (s *stubStore) RemoveReviewer(ctx context.Context, payload storage.ReviewerPayload) (*storage.PullRequest, error) {
+	if s.rmReviewer != nil {
+		return s.rmReviewer(ctx, payload)
+	}
+	return &storage.PullRequest{ID: payload.PRID, AssignedReviewers: []string{"u2"}}, nil
+}
+
+func (s *stubStore) UserReviews(_ context.Context, userID string, filter storage.PRFilter) (*storage.PRPage, error) {
 	if s.userReviews != nil {
-		return s.userReviews(context.Background(), userID)
+		return s.userReviews(context.Background(), userID, filter)
 	}
-	return []storage.PullRequestShort{{ID: "pr1", AuthorID: userID}}, nil
+	return &storage.PRPage{PullRequests: []storage.PullRequest{{ID: "pr1", AuthorID: "u2", AssignedReviewers: []string{userID}}}}, nil
 }
 
 func (s *stubStore) Stats(context.Context) (*storage.Stats, error) {
@@ -90,6 +233,122 @@ func (s *stubStore) MassDeactivate(context.Context, string) error {
 	return nil
 }
 
+func (s *stubStore) UpdateTeamSettings(
+	ctx context.Context,
+	payload storage.TeamSettingsPayload,
+) (*storage.TeamSettings, error) {
+	if s.settings != nil {
+		return s.settings(ctx, payload)
+	}
+	return &storage.TeamSettings{TeamName: payload.TeamName, MinReviewers: 1, MaxReviewers: 2}, nil
+}
+
+func (s *stubStore) SetOwnership(ctx context.Context, payload storage.OwnershipPayload) (*storage.Ownership, error) {
+	if s.setOwners != nil {
+		return s.setOwners(ctx, payload)
+	}
+	return &storage.Ownership{TeamName: payload.TeamName, Rules: []storage.OwnershipRule{}}, nil
+}
+
+func (s *stubStore) TeamOwnership(ctx context.Context, teamName string) (*storage.Ownership, error) {
+	if s.owners != nil {
+		return s.owners(ctx, teamName)
+	}
+	return &storage.Ownership{TeamName: teamName, Rules: []storage.OwnershipRule{}}, nil
+}
+
+func (s *stubStore) PRHistory(ctx context.Context, prID string) ([]storage.AssignmentEvent, error) {
+	if s.history != nil {
+		return s.history(ctx, prID)
+	}
+	return []storage.AssignmentEvent{{PRID: prID, UserID: "u2", Action: storage.EventAssigned}}, nil
+}
+
+func (s *stubStore) SetMaxOpenReviews(
+	ctx context.Context,
+	payload storage.SetMaxOpenReviewsPayload,
+) (*storage.User, error) {
+	if s.setCapacity != nil {
+		return s.setCapacity(ctx, payload)
+	}
+	return &storage.User{ID: payload.UserID, MaxOpenReviews: payload.MaxOpenReviews}, nil
+}
+
+func (s *stubStore) SetUserRole(ctx context.Context, payload storage.SetRolePayload) (*storage.User, error) {
+	if s.setRole != nil {
+		return s.setRole(ctx, payload)
+	}
+	return &storage.User{ID: payload.UserID, Role: payload.Role}, nil
+}
+
+func (s *stubStore) GetUser(ctx context.Context, userID string) (*storage.User, error) {
+	if s.getUser != nil {
+		return s.getUser(ctx, userID)
+	}
+	return &storage.User{ID: userID}, nil
+}
+
+func (s *stubStore) UpdateUser(ctx context.Context, payload storage.UpdateUserPayload) (*storage.User, error) {
+	if s.updateUser != nil {
+		return s.updateUser(ctx, payload)
+	}
+	return &storage.User{ID: payload.UserID, Username: payload.Username}, nil
+}
+
+func (s *stubStore) MoveUserTeam(
+	ctx context.Context,
+	payload storage.MoveTeamPayload,
+) (*storage.User, *storage.ReassignmentSummary, error) {
+	if s.moveTeam != nil {
+		return s.moveTeam(ctx, payload)
+	}
+	return &storage.User{ID: payload.UserID, TeamName: payload.TeamName}, nil, nil
+}
+
+func (s *stubStore) DeleteUser(ctx context.Context, userID string) (*storage.DeletedUser, error) {
+	if s.deleteUser != nil {
+		return s.deleteUser(ctx, userID)
+	}
+	return &storage.DeletedUser{UserID: userID}, nil
+}
+
+func (s *stubStore) AddAbsence(ctx context.Context, payload storage.AbsencePayload) (*storage.Absence, error) {
+	if s.addAbsence != nil {
+		return s.addAbsence(ctx, payload)
+	}
+	return &storage.Absence{
+		ID:       1,
+		UserID:   payload.UserID,
+		StartsAt: payload.StartsAt,
+		EndsAt:   payload.EndsAt,
+		Reason:   payload.Reason,
+	}, nil
+}
+
+func (s *stubStore) UserAbsences(ctx context.Context, userID string) ([]storage.Absence, error) {
+	if s.absences != nil {
+		return s.absences(ctx, userID)
+	}
+	return []storage.Absence{{ID: 1, UserID: userID}}, nil
+}
+
+func (s *stubStore) UpdateAbsence(
+	ctx context.Context,
+	payload storage.AbsenceUpdatePayload,
+) (*storage.Absence, error) {
+	if s.updAbsence != nil {
+		return s.updAbsence(ctx, payload)
+	}
+	return &storage.Absence{ID: payload.ID}, nil
+}
+
+func (s *stubStore) RemoveAbsence(ctx context.Context, id int64) error {
+	if s.rmAbsence != nil {
+		return s.rmAbsence(ctx, id)
+	}
+	return nil
+}
+
 func newTestServer(t *testing.T, store service.Store) *server {
 	t.Helper()
 	logger := zaptest.NewLogger(t).Sugar()
@@ -197,7 +456,31 @@ func TestMapErrorWithLog(t *testing.T) {
 		{storage.ErrPRMerged, "PR_MERGED", http.StatusConflict},
 		{storage.ErrNotAssigned, "NOT_ASSIGNED", http.StatusConflict},
 		{storage.ErrNoCandidate, "NO_CANDIDATE", http.StatusConflict},
+		{storage.ErrInvalidReviewerCount, "INVALID_REVIEWER_COUNT", http.StatusBadRequest},
+		{storage.ErrInvalidAbsence, "INVALID_ABSENCE", http.StatusBadRequest},
+		{storage.ErrInvalidCapacity, "INVALID_CAPACITY", http.StatusBadRequest},
+		{storage.ErrInvalidFallback, "INVALID_FALLBACK", http.StatusBadRequest},
+		{storage.ErrInvalidPairingWindow, "INVALID_PAIRING_WINDOW", http.StatusBadRequest},
+		{storage.ErrInvalidTag, "INVALID_TAG", http.StatusBadRequest},
+		{storage.ErrTagsUncovered, "TAGS_UNCOVERED", http.StatusConflict},
+		{storage.ErrInvalidOwnership, "INVALID_OWNERSHIP", http.StatusBadRequest},
+		{storage.ErrInvalidRole, "INVALID_ROLE", http.StatusBadRequest},
+		{storage.ErrInvalidPolicy, "INVALID_POLICY", http.StatusBadRequest},
+		{storage.ErrPolicyUnsatisfiable, "POLICY_UNSATISFIABLE", http.StatusConflict},
+		{storage.ErrInvalidReviewers, "INVALID_REVIEWERS", http.StatusBadRequest},
+		{storage.ErrIneligibleReviewer, "INELIGIBLE_REVIEWER", http.StatusConflict},
+		{storage.ErrAlreadyAssigned, "ALREADY_ASSIGNED", http.StatusConflict},
+		{storage.ErrInvalidStatus, "INVALID_STATUS", http.StatusConflict},
+		{storage.ErrInvalidVerdict, "INVALID_VERDICT", http.StatusBadRequest},
+		{storage.ErrInvalidApprovals, "INVALID_APPROVALS", http.StatusBadRequest},
+		{storage.ErrNotEnoughApprovals, "NOT_ENOUGH_APPROVALS", http.StatusConflict},
+		{storage.ErrInvalidFilter, "INVALID_FILTER", http.StatusBadRequest},
+		{storage.ErrMemberOfOtherTeam, "MEMBER_OF_OTHER_TEAM", http.StatusConflict},
+		{storage.ErrNotTeamMember, "NOT_TEAM_MEMBER", http.StatusConflict},
+		{storage.ErrMemberHasOpenPRs, "MEMBER_HAS_OPEN_PRS", http.StatusConflict},
+		{storage.ErrTeamNotEmpty, "TEAM_NOT_EMPTY", http.StatusConflict},
 		{storage.ErrUserNotFound, "NOT_FOUND", http.StatusNotFound},
+		{storage.ErrAbsenceNotFound, "NOT_FOUND", http.StatusNotFound},
 		{errors.New("boom"), "INTERNAL", http.StatusInternalServerError},
 	}
 	for _, tc := range cases {
@@ -227,6 +510,41 @@ func TestHandleSetIsActiveSuccess(t *testing.T) {
 	}
 }
 
+func TestHandleSetIsActiveReturnsReassignment(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		setIsActive: func(
+			ctx context.Context,
+			payload storage.SetActivePayload,
+		) (*storage.User, *storage.ReassignmentSummary, error) {
+			return &storage.User{ID: payload.UserID}, &storage.ReassignmentSummary{
+				Reassigned: []storage.Reassignment{{PRID: "pr1", OldUserID: "u1", NewUserID: "u3"}},
+				Unfilled:   []string{"pr2"},
+			}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/users/setIsActive", `{"user_id":"u1","is_active":false}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		Reassignment *storage.ReassignmentSummary `json:"reassignment"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Reassignment == nil || len(out.Reassignment.Reassigned) != 1 || len(out.Reassignment.Unfilled) != 1 {
+		t.Fatalf("unexpected reassignment: %+v", out.Reassignment)
+	}
+}
+
 func TestHandleGetReviewSuccess(t *testing.T) {
 	srv := newTestServer(t, &stubStore{})
 	ts := httptest.NewServer(srv.Routes())
@@ -251,6 +569,68 @@ func TestHandleGetReviewSuccess(t *testing.T) {
 	}
 }
 
+func TestHandleGetReviewFiltersAndFull(t *testing.T) {
+	var got storage.PRFilter
+	srv := newTestServer(t, &stubStore{
+		userReviews: func(ctx context.Context, userID string, filter storage.PRFilter) (*storage.PRPage, error) {
+			got = filter
+			return &storage.PRPage{
+				PullRequests: []storage.PullRequest{{ID: "pr1", AuthorID: "u2", AssignedReviewers: []string{userID, "u3"}}},
+				NextCursor:   "next",
+			}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	resp, err := ts.Client().Get(ts.URL + "/users/getReview?user_id=u1&status=OPEN&sort=created_at&limit=1&cursor=abc&full=true")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	var out struct {
+		PullRequests []storage.PullRequest `json:"pull_requests"`
+		NextCursor   string                `json:"next_cursor"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || out.NextCursor != "next" ||
+		len(out.PullRequests) != 1 || len(out.PullRequests[0].AssignedReviewers) != 2 {
+		t.Fatalf("status = %d, body = %+v", resp.StatusCode, out)
+	}
+	want := storage.PRFilter{Status: storage.StatusOpen, Sort: storage.SortCreated, Limit: 1, Cursor: "abc"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("filter = %+v, want %+v", got, want)
+	}
+
+	resp, err = ts.Client().Get(ts.URL + "/users/getReview?user_id=u1")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	var short map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&short); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	prs, _ := short["pull_requests"].([]any)
+	if len(prs) != 1 {
+		t.Fatalf("unexpected body: %+v", short)
+	}
+	if _, ok := prs[0].(map[string]any)["assigned_reviewers"]; ok {
+		t.Fatalf("short PR carries reviewers: %+v", prs[0])
+	}
+
+	resp, err = ts.Client().Get(ts.URL + "/users/getReview?user_id=u1&full=maybe")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("invalid full: status = %d", resp.StatusCode)
+	}
+}
+
 func TestHandleCreatePRSuccess(t *testing.T) {
 	srv := newTestServer(t, &stubStore{})
 	ts := httptest.NewServer(srv.Routes())
@@ -297,6 +677,55 @@ func TestHandleCreatePRExists(t *testing.T) {
 	}
 }
 
+func TestHandleCreatePRRequestedReviewers(t *testing.T) {
+	var got storage.CreatePRPayload
+	srv := newTestServer(t, &stubStore{
+		createPR: func(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error) {
+			got = payload
+			if len(payload.RequestedReviewers) > 1 {
+				return nil, fmt.Errorf("%w: unknown user %q", storage.ErrInvalidReviewers, payload.RequestedReviewers[1])
+			}
+			return &storage.PullRequest{ID: payload.ID, AssignedReviewers: payload.RequestedReviewers}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/create",
+		`{"pull_request_id":"pr1","pull_request_name":"Feature","author_id":"u1","requested_reviewers":["u2"],"excluded_reviewers":["u3"]}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if !reflect.DeepEqual(got.RequestedReviewers, []string{"u2"}) || !reflect.DeepEqual(got.ExcludedReviewers, []string{"u3"}) {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+
+	req = newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/create",
+		`{"pull_request_id":"pr1","pull_request_name":"Feature","author_id":"u1","requested_reviewers":["u2","ghost"]}`)
+	resp, err = ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest || out.Error.Code != "INVALID_REVIEWERS" || !strings.Contains(out.Error.Message, "ghost") {
+		t.Fatalf("status = %d, error = %+v", resp.StatusCode, out.Error)
+	}
+}
+
 func TestHandleMergePRSuccess(t *testing.T) {
 	srv := newTestServer(t, &stubStore{})
 	ts := httptest.NewServer(srv.Routes())
@@ -359,111 +788,417 @@ func TestHandleReassignSuccess(t *testing.T) {
 	}
 }
 
-func TestHandleReassignErrors(t *testing.T) {
-	cases := []struct {
-		err         error
-		wantStatus  int
-		description string
-	}{
-		{storage.ErrPRMerged, http.StatusConflict, "merged"},
-		{storage.ErrNotAssigned, http.StatusConflict, "not assigned"},
-		{storage.ErrNoCandidate, http.StatusConflict, "no candidate"},
-		{storage.ErrPRNotFound, http.StatusNotFound, "pr not found"},
-		{storage.ErrUserNotFound, http.StatusNotFound, "user not found"},
-		{storage.ErrTeamNotFound, http.StatusNotFound, "team not found"},
-	}
-	for _, tc := range cases {
-		tc := tc
-		t.Run(tc.description, func(t *testing.T) {
-			srv := newTestServer(t, &stubStore{
-				reassign: func(ctx context.Context, payload storage.ReassignPayload) (*storage.PullRequest, string, error) {
-					return nil, "", tc.err
-				},
-			})
-			ts := httptest.NewServer(srv.Routes())
-			t.Cleanup(ts.Close)
-
-			req := newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/reassign", `{"pull_request_id":"pr1","old_user_id":"u1"}`)
-			resp, err := ts.Client().Do(req)
-			if err != nil {
-				t.Fatalf("do: %v", err)
-			}
-			defer resp.Body.Close()
-			if resp.StatusCode != tc.wantStatus {
-				t.Fatalf("status = %d, want %d", resp.StatusCode, tc.wantStatus)
-			}
-		})
-	}
-}
-
-func TestHandleStatsSuccess(t *testing.T) {
+func TestHandleAddAndRemoveReviewer(t *testing.T) {
 	srv := newTestServer(t, &stubStore{})
 	ts := httptest.NewServer(srv.Routes())
 	t.Cleanup(ts.Close)
 
-	req, err := http.NewRequestWithContext(
-		context.Background(),
-		http.MethodGet,
-		ts.URL+"/stats",
-		http.NoBody,
-	)
-	if err != nil {
-		t.Fatalf("new request: %v", err)
-	}
-	resp, err := ts.Client().Do(req)
-	if err != nil {
-		t.Fatalf("do: %v", err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("status = %d", resp.StatusCode)
+	for path, want := range map[string]int{"/pullRequest/addReviewer": 2, "/pullRequest/removeReviewer": 1} {
+		req := newJSONRequest(t, http.MethodPost, ts.URL+path, `{"pull_request_id":"pr1","user_id":"u3"}`)
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatalf("do: %v", err)
+		}
+		var out struct {
+			PR storage.PullRequest `json:"pr"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&out)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK || len(out.PR.AssignedReviewers) != want {
+			t.Fatalf("%s: status = %d, pr = %+v", path, resp.StatusCode, out.PR)
+		}
+
+		req = newJSONRequest(t, http.MethodPost, ts.URL+path, `{"pull_request_id":"pr1"}`)
+		resp, err = ts.Client().Do(req)
+		if err != nil {
+			t.Fatalf("do: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s without user_id: status = %d", path, resp.StatusCode)
+		}
 	}
 }
 
-func TestHandleAddTeamNotFound(t *testing.T) {
+func TestHandleAddReviewerMerged(t *testing.T) {
 	srv := newTestServer(t, &stubStore{
-		addTeam: func(ctx context.Context, payload storage.TeamPayload) (storage.TeamPayload, error) {
-			return storage.TeamPayload{}, storage.ErrTeamNotFound
+		addReviewer: func(ctx context.Context, payload storage.ReviewerPayload) (*storage.PullRequest, error) {
+			return nil, storage.ErrPRMerged
 		},
 	})
 	ts := httptest.NewServer(srv.Routes())
 	t.Cleanup(ts.Close)
 
-	body := bytes.NewBufferString(`{"team_name":"x","members":[]}`)
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, ts.URL+"/team/add", body)
-	if err != nil {
-		t.Fatalf("new request: %v", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/addReviewer", `{"pull_request_id":"pr1","user_id":"u3"}`)
 	resp, err := ts.Client().Do(req)
 	if err != nil {
 		t.Fatalf("do: %v", err)
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusNotFound {
-		t.Fatalf("status = %d", resp.StatusCode)
+	var out struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.StatusCode != http.StatusConflict || out.Error.Code != "PR_MERGED" ||
+		out.Error.Message != "cannot add a reviewer to merged PR" {
+		t.Fatalf("status = %d, error = %+v", resp.StatusCode, out.Error)
 	}
 }
 
-func TestHandleAddTeamExists(t *testing.T) {
-	srv := newTestServer(t, &stubStore{
-		addTeam: func(ctx context.Context, payload storage.TeamPayload) (storage.TeamPayload, error) {
-			return storage.TeamPayload{}, storage.ErrTeamExists
-		},
-	})
+func TestHandleSubmitReview(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
 	ts := httptest.NewServer(srv.Routes())
 	t.Cleanup(ts.Close)
 
-	req := newJSONRequest(t, http.MethodPost, ts.URL+"/team/add", `{"team_name":"backend","members":[]}`)
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/submitReview", `{"pull_request_id":"pr1","user_id":"u1","verdict":"APPROVED"}`)
 	resp, err := ts.Client().Do(req)
 	if err != nil {
 		t.Fatalf("do: %v", err)
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Fatalf("status = %d", resp.StatusCode)
+	var out struct {
+		PR storage.PullRequest `json:"pr"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || len(out.PR.Reviews) != 1 || out.PR.Reviews[0].Verdict != storage.VerdictApproved {
+		t.Fatalf("status = %d, pr = %+v", resp.StatusCode, out.PR)
 	}
-}
+
+	req = newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/submitReview", `{"pull_request_id":"pr1","user_id":"u1"}`)
+	resp, err = ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("without verdict: status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandleMergePRNotEnoughApprovals(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		merge: func(ctx context.Context, id string) (*storage.PullRequest, error) {
+			return nil, fmt.Errorf("%w: 0 of 1 required approvals", storage.ErrNotEnoughApprovals)
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/merge", `{"pull_request_id":"pr1"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.StatusCode != http.StatusConflict || out.Error.Code != "NOT_ENOUGH_APPROVALS" || !strings.Contains(out.Error.Message, "0 of 1") {
+		t.Fatalf("status = %d, error = %+v", resp.StatusCode, out.Error)
+	}
+}
+
+func TestHandleGetPR(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		getPR: func(ctx context.Context, id string) (*storage.PullRequest, error) {
+			if id != "pr1" {
+				return nil, storage.ErrPRNotFound
+			}
+			return &storage.PullRequest{ID: id, AssignedReviewers: []string{"u2"}}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	for query, want := range map[string]int{
+		"":                     http.StatusBadRequest,
+		"?pull_request_id=pr1": http.StatusOK,
+		"?pull_request_id=pr9": http.StatusNotFound,
+	} {
+		resp, err := ts.Client().Get(ts.URL + "/pullRequest/get" + query)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("%q: status = %d, want %d", query, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestHandleListPRs(t *testing.T) {
+	var got storage.PRFilter
+	srv := newTestServer(t, &stubStore{
+		listPRs: func(ctx context.Context, filter storage.PRFilter) (*storage.PRPage, error) {
+			got = filter
+			return &storage.PRPage{
+				PullRequests: []storage.PullRequest{{ID: "pr1", AssignedReviewers: []string{}}},
+				NextCursor:   "next",
+			}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	resp, err := ts.Client().Get(ts.URL + "/pullRequest/list?status=OPEN&reviewer_id=u1&team_name=backend&limit=10&created_from=2025-07-01T00:00:00Z&cursor=abc")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	var page storage.PRPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || len(page.PullRequests) != 1 || page.NextCursor != "next" {
+		t.Fatalf("status = %d, page = %+v", resp.StatusCode, page)
+	}
+	wantFrom := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	if got.Status != storage.StatusOpen || got.ReviewerID != "u1" || got.TeamName != "backend" || got.Limit != 10 ||
+		got.Cursor != "abc" || got.CreatedFrom == nil || !got.CreatedFrom.Equal(wantFrom) || got.MergedTo != nil {
+		t.Fatalf("unexpected filter: %+v", got)
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=x", "?merged_to=yesterday"} {
+		resp, err := ts.Client().Get(ts.URL + "/pullRequest/list" + query)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandlePRLifecycle(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	for path, want := range map[string]string{
+		"/pullRequest/close":  storage.StatusClosed,
+		"/pullRequest/ready":  storage.StatusOpen,
+		"/pullRequest/reopen": storage.StatusOpen,
+	} {
+		req := newJSONRequest(t, http.MethodPost, ts.URL+path, `{"pull_request_id":"pr1"}`)
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatalf("do: %v", err)
+		}
+		var out struct {
+			PR storage.PullRequest `json:"pr"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&out)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK || out.PR.Status != want {
+			t.Fatalf("%s: status = %d, pr = %+v", path, resp.StatusCode, out.PR)
+		}
+
+		req = newJSONRequest(t, http.MethodPost, ts.URL+path, `{}`)
+		resp, err = ts.Client().Do(req)
+		if err != nil {
+			t.Fatalf("do: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s without pull_request_id: status = %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandleReadyPRInvalidStatus(t *testing.T) {
+	var explain bool
+	srv := newTestServer(t, &stubStore{
+		openPR: func(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error) {
+			explain = payload.Explain
+			return nil, fmt.Errorf("%w: expected a DRAFT PR, got OPEN", storage.ErrInvalidStatus)
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/ready?explain=true", `{"pull_request_id":"pr1"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.StatusCode != http.StatusConflict || out.Error.Code != "INVALID_STATUS" || !explain {
+		t.Fatalf("status = %d, code = %s, explain = %v", resp.StatusCode, out.Error.Code, explain)
+	}
+}
+
+func TestHandleReassignToChosenReviewer(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		reassign: func(ctx context.Context, payload storage.ReassignPayload) (*storage.PullRequest, string, error) {
+			if payload.New == "u1" {
+				return nil, "", storage.ErrAlreadyAssigned
+			}
+			return &storage.PullRequest{ID: payload.PRID}, payload.New, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/reassign", `{"pull_request_id":"pr1","old_user_id":"u1","new_user_id":"u3"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	var out struct {
+		ReplacedBy string `json:"replaced_by"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || out.ReplacedBy != "u3" {
+		t.Fatalf("status = %d, replaced_by = %q", resp.StatusCode, out.ReplacedBy)
+	}
+
+	req = newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/reassign", `{"pull_request_id":"pr1","old_user_id":"u1","new_user_id":"u1"}`)
+	resp2, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp2.Body.Close()
+	if resp2.StatusCode != http.StatusConflict {
+		t.Fatalf("status = %d", resp2.StatusCode)
+	}
+}
+
+func TestHandleReassignErrors(t *testing.T) {
+	cases := []struct {
+		err         error
+		wantStatus  int
+		description string
+	}{
+		{storage.ErrPRMerged, http.StatusConflict, "merged"},
+		{storage.ErrNotAssigned, http.StatusConflict, "not assigned"},
+		{storage.ErrNoCandidate, http.StatusConflict, "no candidate"},
+		{storage.ErrPRNotFound, http.StatusNotFound, "pr not found"},
+		{storage.ErrUserNotFound, http.StatusNotFound, "user not found"},
+		{storage.ErrTeamNotFound, http.StatusNotFound, "team not found"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			srv := newTestServer(t, &stubStore{
+				reassign: func(ctx context.Context, payload storage.ReassignPayload) (*storage.PullRequest, string, error) {
+					return nil, "", tc.err
+				},
+			})
+			ts := httptest.NewServer(srv.Routes())
+			t.Cleanup(ts.Close)
+
+			req := newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/reassign", `{"pull_request_id":"pr1","old_user_id":"u1"}`)
+			resp, err := ts.Client().Do(req)
+			if err != nil {
+				t.Fatalf("do: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandleStatsSuccess(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req, err := http.NewRequestWithContext(
+		context.Background(),
+		http.MethodGet,
+		ts.URL+"/stats",
+		http.NoBody,
+	)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandleAddTeamNotFound(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		addTeam: func(ctx context.Context, payload storage.TeamPayload) (storage.TeamPayload, error) {
+			return storage.TeamPayload{}, storage.ErrTeamNotFound
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	body := bytes.NewBufferString(`{"team_name":"x","members":[]}`)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, ts.URL+"/team/add", body)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandleAddTeamExists(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		addTeam: func(ctx context.Context, payload storage.TeamPayload) (storage.TeamPayload, error) {
+			return storage.TeamPayload{}, storage.ErrTeamExists
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/team/add", `{"team_name":"backend","members":[]}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
 
 func TestHandleAddTeamSuccess(t *testing.T) {
 	srv := newTestServer(t, &stubStore{})
@@ -486,6 +1221,22 @@ func TestHandleAddTeamSuccess(t *testing.T) {
 	}
 }
 
+func TestHandleAddTeamUnknownStrategy(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/team/add", `{"team_name":"backend","reviewer_strategy":"fastest","members":[]}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
 func TestHandleDeactivateTeamSuccess(t *testing.T) {
 	srv := newTestServer(t, &stubStore{})
 	ts := httptest.NewServer(srv.Routes())
@@ -556,8 +1307,11 @@ func TestHandleGetTeamNotFound(t *testing.T) {
 
 func TestHandleSetActiveNotFound(t *testing.T) {
 	srv := newTestServer(t, &stubStore{
-		setIsActive: func(ctx context.Context, payload storage.SetActivePayload) (*storage.User, error) {
-			return nil, storage.ErrUserNotFound
+		setIsActive: func(
+			ctx context.Context,
+			payload storage.SetActivePayload,
+		) (*storage.User, *storage.ReassignmentSummary, error) {
+			return nil, nil, storage.ErrUserNotFound
 		},
 	})
 	ts := httptest.NewServer(srv.Routes())
@@ -586,7 +1340,7 @@ func TestHandleSetActiveNotFound(t *testing.T) {
 
 func TestHandleGetReviewNotFound(t *testing.T) {
 	srv := newTestServer(t, &stubStore{
-		userReviews: func(ctx context.Context, userID string) ([]storage.PullRequestShort, error) {
+		userReviews: func(ctx context.Context, userID string, filter storage.PRFilter) (*storage.PRPage, error) {
 			return nil, storage.ErrUserNotFound
 		},
 	})
@@ -623,3 +1377,864 @@ func TestHandleStatsInternal(t *testing.T) {
 		t.Fatalf("status = %d", resp.StatusCode)
 	}
 }
+
+func TestHandleSetTeamSettingsSuccess(t *testing.T) {
+	var got storage.TeamSettingsPayload
+	srv := newTestServer(t, &stubStore{
+		settings: func(_ context.Context, payload storage.TeamSettingsPayload) (*storage.TeamSettings, error) {
+			got = payload
+			return &storage.TeamSettings{TeamName: payload.TeamName, MinReviewers: 3, MaxReviewers: 3}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/team/setSettings", `{"team_name":"platform","min_reviewers":3,"max_reviewers":3}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if got.MinReviewers == nil || *got.MinReviewers != 3 || got.ReviewerStrategy != nil {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+}
+
+func TestHandleSetTeamSettingsInvalidBounds(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		settings: func(context.Context, storage.TeamSettingsPayload) (*storage.TeamSettings, error) {
+			return nil, storage.ErrInvalidReviewerCount
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/team/setSettings", `{"team_name":"docs","min_reviewers":3,"max_reviewers":1}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandlePRHistorySuccess(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodGet, ts.URL+"/pullRequest/history?pull_request_id=pr1", "")
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		Events []storage.AssignmentEvent `json:"events"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(out.Events) != 1 || out.Events[0].UserID != "u2" {
+		t.Fatalf("unexpected events: %+v", out.Events)
+	}
+}
+
+func TestHandlePRHistoryValidation(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodGet, ts.URL+"/pullRequest/history", "")
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandlePRHistoryNotFound(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		history: func(context.Context, string) ([]storage.AssignmentEvent, error) {
+			return nil, storage.ErrPRNotFound
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodGet, ts.URL+"/pullRequest/history?pull_request_id=pr404", "")
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandleAddAbsenceSuccess(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/users/addAbsence",
+		`{"user_id":"u1","starts_at":"2025-07-01T00:00:00Z","ends_at":"2025-07-15T00:00:00Z","reason":"vacation"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		Absence storage.Absence `json:"absence"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Absence.UserID != "u1" || out.Absence.Reason != "vacation" || out.Absence.EndsAt.Day() != 15 {
+		t.Fatalf("unexpected absence: %+v", out.Absence)
+	}
+}
+
+func TestHandleAddAbsenceValidation(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/users/addAbsence", `{"user_id":"u1"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandleGetAbsencesSuccess(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodGet, ts.URL+"/users/getAbsences?user_id=u1", "")
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		Absences []storage.Absence `json:"absences"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(out.Absences) != 1 || out.Absences[0].UserID != "u1" {
+		t.Fatalf("unexpected absences: %+v", out.Absences)
+	}
+}
+
+func TestHandleUpdateAbsenceInvalid(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		updAbsence: func(ctx context.Context, payload storage.AbsenceUpdatePayload) (*storage.Absence, error) {
+			return nil, storage.ErrInvalidAbsence
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/users/updateAbsence",
+		`{"absence_id":1,"ends_at":"2020-01-01T00:00:00Z"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandleRemoveAbsenceNotFound(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		rmAbsence: func(ctx context.Context, id int64) error {
+			return storage.ErrAbsenceNotFound
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/users/removeAbsence", `{"absence_id":42}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandleSetMaxOpenReviewsSuccess(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/users/setMaxOpenReviews", `{"user_id":"u1","max_open_reviews":2}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		User storage.User `json:"user"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.User.MaxOpenReviews != 2 {
+		t.Fatalf("unexpected user: %+v", out.User)
+	}
+}
+
+func TestHandleSetMaxOpenReviewsInvalid(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		setCapacity: func(ctx context.Context, payload storage.SetMaxOpenReviewsPayload) (*storage.User, error) {
+			return nil, storage.ErrInvalidCapacity
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/users/setMaxOpenReviews", `{"user_id":"u1","max_open_reviews":-1}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandleSetRoleSuccess(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/users/setRole", `{"user_id":"u1","role":"senior"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		User storage.User `json:"user"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.User.Role != storage.RoleSenior {
+		t.Fatalf("unexpected user: %+v", out.User)
+	}
+}
+
+func TestHandleSetRoleInvalid(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		setRole: func(ctx context.Context, payload storage.SetRolePayload) (*storage.User, error) {
+			return nil, storage.ErrInvalidRole
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	for _, body := range []string{`{"role":"senior"}`, `{"user_id":"u1","role":"boss"}`} {
+		req := newJSONRequest(t, http.MethodPost, ts.URL+"/users/setRole", body)
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatalf("do: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d", body, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandleCreatePRExplainFlag(t *testing.T) {
+	var gotExplain bool
+	srv := newTestServer(t, &stubStore{
+		createPR: func(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error) {
+			gotExplain = payload.Explain
+			return &storage.PullRequest{
+				ID:          payload.ID,
+				Explanation: []storage.SelectionExplanation{{TeamName: "backend", Strategy: storage.StrategyRandom}},
+			}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/create?explain=true",
+		`{"pull_request_id":"pr1","pull_request_name":"n","author_id":"u1"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if !gotExplain {
+		t.Fatal("explain flag was not passed to the store")
+	}
+	var out struct {
+		PR storage.PullRequest `json:"pr"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(out.PR.Explanation) != 1 || out.PR.Explanation[0].Strategy != storage.StrategyRandom {
+		t.Fatalf("unexpected explanation: %+v", out.PR.Explanation)
+	}
+}
+
+func TestHandleReassignExplainInvalid(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/reassign?explain=maybe",
+		`{"pull_request_id":"pr1","old_user_id":"u1"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandlePreviewPR(t *testing.T) {
+	var got storage.CreatePRPayload
+	srv := newTestServer(t, &stubStore{
+		preview: func(ctx context.Context, payload storage.CreatePRPayload) (*storage.AssignmentPreview, error) {
+			got = payload
+			return &storage.AssignmentPreview{AuthorID: payload.Author, AssignedReviewers: []string{"u2", "u3"}}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/preview?explain=true",
+		`{"author_id":"u1","reviewers_count":2}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if got.Author != "u1" || !got.Explain || got.ReviewersCount == nil || *got.ReviewersCount != 2 {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+	var out struct {
+		Preview storage.AssignmentPreview `json:"preview"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(out.Preview.AssignedReviewers) != 2 {
+		t.Fatalf("unexpected preview: %+v", out.Preview)
+	}
+}
+
+func TestHandlePreviewPRValidation(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/pullRequest/preview", `{"pull_request_id":"pr1"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandleSetOwnership(t *testing.T) {
+	var got storage.OwnershipPayload
+	srv := newTestServer(t, &stubStore{
+		setOwners: func(ctx context.Context, payload storage.OwnershipPayload) (*storage.Ownership, error) {
+			got = payload
+			return &storage.Ownership{TeamName: payload.TeamName, Rules: []storage.OwnershipRule{
+				{Pattern: "*.go", Owners: []storage.Owner{{UserID: "u1"}}},
+			}}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/team/setOwnership",
+		`{"team_name":"backend","codeowners":"*.go @u1\n"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if got.TeamName != "backend" || got.CodeOwners != "*.go @u1\n" {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+}
+
+func TestHandleSetOwnershipInvalid(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		setOwners: func(ctx context.Context, payload storage.OwnershipPayload) (*storage.Ownership, error) {
+			return nil, fmt.Errorf("%w: unknown user %q", storage.ErrInvalidOwnership, "ghost")
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/team/setOwnership", `{"team_name":"backend","codeowners":"* @ghost"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Error.Code != "INVALID_OWNERSHIP" || !strings.Contains(out.Error.Message, "ghost") {
+		t.Fatalf("unexpected error: %+v", out.Error)
+	}
+}
+
+func TestHandleGetOwnershipValidation(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodGet, ts.URL+"/team/getOwnership", "")
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandleGetUser(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		getUser: func(ctx context.Context, userID string) (*storage.User, error) {
+			if userID == "ghost" {
+				return nil, storage.ErrUserNotFound
+			}
+			return &storage.User{ID: userID, Username: "Alice", TeamName: "backend"}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	resp, err := ts.Client().Get(ts.URL + "/users/get?user_id=u1")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		User storage.User `json:"user"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.User.ID != "u1" || out.User.Username != "Alice" {
+		t.Fatalf("unexpected user: %+v", out.User)
+	}
+
+	missing, err := ts.Client().Get(ts.URL + "/users/get?user_id=ghost")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer missing.Body.Close()
+	if missing.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d", missing.StatusCode)
+	}
+}
+
+func TestHandleUpdateUser(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	cases := []struct {
+		body string
+		want int
+	}{
+		{`{"user_id":"u1","username":"Alicia"}`, http.StatusOK},
+		{`{"user_id":"u1"}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		req := newJSONRequest(t, http.MethodPatch, ts.URL+"/users/update", tc.body)
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatalf("do: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Fatalf("%s: status = %d, want %d", tc.body, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestHandleMoveTeam(t *testing.T) {
+	var got storage.MoveTeamPayload
+	srv := newTestServer(t, &stubStore{
+		moveTeam: func(
+			ctx context.Context,
+			payload storage.MoveTeamPayload,
+		) (*storage.User, *storage.ReassignmentSummary, error) {
+			got = payload
+			return &storage.User{ID: payload.UserID, TeamName: payload.TeamName}, &storage.ReassignmentSummary{
+				Reassigned: []storage.Reassignment{{PRID: "pr1", OldUserID: "u1", NewUserID: "u3"}},
+			}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/users/moveTeam", `{"user_id":"u1","team_name":"platform"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		User         storage.User                 `json:"user"`
+		Reassignment *storage.ReassignmentSummary `json:"reassignment"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.KeepReviews || out.User.TeamName != "platform" || out.Reassignment == nil || len(out.Reassignment.Reassigned) != 1 {
+		t.Fatalf("unexpected response: %+v (payload %+v)", out, got)
+	}
+}
+
+func TestHandleMoveTeamValidation(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/users/moveTeam", `{"user_id":"u1"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandleDeleteUser(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		deleteUser: func(ctx context.Context, userID string) (*storage.DeletedUser, error) {
+			return &storage.DeletedUser{
+				UserID:       userID,
+				Reassignment: &storage.ReassignmentSummary{Unfilled: []string{"pr2"}},
+				ClosedPRs:    []string{"pr7"},
+				OwnershipRules: []storage.OwnedRule{
+					{TeamName: "backend", Pattern: "*.sql", Orphaned: true},
+				},
+			}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodDelete, ts.URL+"/users/delete?user_id=u1", "")
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		UserID         string              `json:"user_id"`
+		Status         string              `json:"status"`
+		ClosedPRs      []string            `json:"closed_prs"`
+		OwnershipRules []storage.OwnedRule `json:"ownership_rules"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.UserID != "u1" || out.Status != "deleted" || !reflect.DeepEqual(out.ClosedPRs, []string{"pr7"}) ||
+		len(out.OwnershipRules) != 1 || !out.OwnershipRules[0].Orphaned {
+		t.Fatalf("unexpected response: %+v", out)
+	}
+}
+
+func TestHandleAddMembers(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	cases := []struct {
+		body string
+		want int
+	}{
+		{`{"team_name":"backend","members":[{"user_id":"u3","username":"Carol","is_active":true}]}`, http.StatusOK},
+		{`{"team_name":"backend","members":[]}`, http.StatusBadRequest},
+		{`{"team_name":"backend","members":[{"username":"Carol"}]}`, http.StatusBadRequest},
+		{`{"team_name":"backend","members":[{"user_id":"u3","review_weight":-1}]}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		req := newJSONRequest(t, http.MethodPost, ts.URL+"/team/addMembers", tc.body)
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatalf("do: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Fatalf("%s: status = %d, want %d", tc.body, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestHandleAddMembersOtherTeam(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		addMembers: func(ctx context.Context, payload storage.MembersPayload) (storage.TeamPayload, error) {
+			return storage.TeamPayload{}, fmt.Errorf("%w: %q is in team %q", storage.ErrMemberOfOtherTeam, "u9", "platform")
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/team/addMembers", `{"team_name":"backend","members":[{"user_id":"u9"}]}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestHandleRemoveMembers(t *testing.T) {
+	var got storage.RemoveMembersPayload
+	srv := newTestServer(t, &stubStore{
+		rmMembers: func(ctx context.Context, payload storage.RemoveMembersPayload) (*storage.RemovedMembers, error) {
+			got = payload
+			return &storage.RemovedMembers{
+				Team:    storage.TeamPayload{TeamName: payload.TeamName},
+				Removed: []storage.RemovedMember{{User: &storage.User{ID: "u2"}}},
+			}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/team/removeMembers",
+		`{"team_name":"backend","user_ids":["u2"]}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out storage.RemovedMembers
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !reflect.DeepEqual(got.UserIDs, []string{"u2"}) || out.Team.TeamName != "backend" {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+	if len(out.Removed) != 1 || out.Removed[0].User.ID != "u2" || out.Removed[0].User.TeamName != "" {
+		t.Fatalf("unexpected response: %+v", out)
+	}
+}
+
+func TestHandleRemoveMembersValidation(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	for _, body := range []string{
+		`{"user_ids":["u2"]}`,
+		`{"team_name":"backend"}`,
+		`{"team_name":"backend","user_ids":[]}`,
+	} {
+		req := newJSONRequest(t, http.MethodPost, ts.URL+"/team/removeMembers", body)
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatalf("do: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d", body, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandleRenameTeam(t *testing.T) {
+	srv := newTestServer(t, &stubStore{})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodPost, ts.URL+"/team/rename", `{"team_name":"backend","new_team_name":"core"}`)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		Team storage.TeamPayload `json:"team"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Team.TeamName != "core" {
+		t.Fatalf("unexpected team: %+v", out.Team)
+	}
+
+	bad := newJSONRequest(t, http.MethodPost, ts.URL+"/team/rename", `{"team_name":"backend"}`)
+	badResp, err := ts.Client().Do(bad)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer badResp.Body.Close()
+	if badResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d", badResp.StatusCode)
+	}
+}
+
+func TestHandleDeleteTeam(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		deleteTeam: func(ctx context.Context, teamName string) (*storage.DeletedTeam, error) {
+			if teamName == "busy" {
+				return nil, fmt.Errorf("%w: %s", storage.ErrTeamNotEmpty, "u1")
+			}
+			return &storage.DeletedTeam{
+				TeamName:       teamName,
+				OwnershipRules: []storage.OwnedRule{{TeamName: "platform", Pattern: "/api/", Orphaned: true}},
+			}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	req := newJSONRequest(t, http.MethodDelete, ts.URL+"/team/delete?team_name=backend", "")
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		Status         string              `json:"status"`
+		OwnershipRules []storage.OwnedRule `json:"ownership_rules"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Status != "deleted" || len(out.OwnershipRules) != 1 || out.OwnershipRules[0].TeamName != "platform" {
+		t.Fatalf("unexpected response: %+v", out)
+	}
+
+	busy := newJSONRequest(t, http.MethodDelete, ts.URL+"/team/delete?team_name=busy", "")
+	busyResp, err := ts.Client().Do(busy)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	defer busyResp.Body.Close()
+	if busyResp.StatusCode != http.StatusConflict {
+		t.Fatalf("status = %d", busyResp.StatusCode)
+	}
+}
+
+func TestHandleListTeams(t *testing.T) {
+	srv := newTestServer(t, &stubStore{
+		listTeams: func(ctx context.Context) ([]storage.TeamSummary, error) {
+			return []storage.TeamSummary{{TeamName: "backend", Members: 3, ActiveMembers: 2, OpenReviews: 4}}, nil
+		},
+	})
+	ts := httptest.NewServer(srv.Routes())
+	t.Cleanup(ts.Close)
+
+	resp, err := ts.Client().Get(ts.URL + "/team/list")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var out struct {
+		Teams []storage.TeamSummary `json:"teams"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := []storage.TeamSummary{{TeamName: "backend", Members: 3, ActiveMembers: 2, OpenReviews: 4}}
+	if !reflect.DeepEqual(out.Teams, want) {
+		t.Fatalf("teams = %+v, want %+v", out.Teams, want)
+	}
+}
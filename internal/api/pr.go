@@ -1,7 +1,11 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"prreviewer/internal/storage"
 )
@@ -23,6 +27,11 @@ func (s *server) handleCreatePR(w http.ResponseWriter, r *http.Request) {
 		)
 		return
 	}
+	explain, ok := s.explainParam(w, r)
+	if !ok {
+		return
+	}
+	payload.Explain = explain
 	pr, err := s.svc.CreatePR(r.Context(), payload)
 	if err != nil {
 		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
@@ -31,6 +40,30 @@ func (s *server) handleCreatePR(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, map[string]any{"pr": pr}, s.logger)
 }
 
+func (s *server) handlePreviewPR(w http.ResponseWriter, r *http.Request) {
+	var payload storage.CreatePRPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.Author == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "author_id is required", s.logger)
+		return
+	}
+	explain, ok := s.explainParam(w, r)
+	if !ok {
+		return
+	}
+	payload.Explain = explain
+	preview, err := s.svc.PreviewPR(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"preview": preview}, s.logger)
+}
+
 func (s *server) handleMergePR(w http.ResponseWriter, r *http.Request) {
 	var payload storage.MergePayload
 	if err := decodeJSON(r, &payload); err != nil {
@@ -50,6 +83,63 @@ func (s *server) handleMergePR(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{"pr": pr}, s.logger)
 }
 
+func (s *server) handleClosePR(w http.ResponseWriter, r *http.Request) {
+	var payload storage.ClosePayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.ID == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id is required", s.logger)
+		return
+	}
+	pr, err := s.svc.ClosePR(r.Context(), payload.ID)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"pr": pr}, s.logger)
+}
+
+func (s *server) handleReadyPR(w http.ResponseWriter, r *http.Request) {
+	s.handleOpenPR(w, r, s.svc.ReadyPR)
+}
+
+func (s *server) handleReopenPR(w http.ResponseWriter, r *http.Request) {
+	s.handleOpenPR(w, r, s.svc.ReopenPR)
+}
+
+// handleOpenPR moves a draft or closed PR to OPEN; the body carries the same
+// selection options as create, the author is taken from the PR.
+func (s *server) handleOpenPR(
+	w http.ResponseWriter,
+	r *http.Request,
+	open func(context.Context, storage.CreatePRPayload) (*storage.PullRequest, error),
+) {
+	var payload storage.CreatePRPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.ID == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id is required", s.logger)
+		return
+	}
+	explain, ok := s.explainParam(w, r)
+	if !ok {
+		return
+	}
+	payload.Explain = explain
+	pr, err := open(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"pr": pr}, s.logger)
+}
+
 func (s *server) handleReassign(w http.ResponseWriter, r *http.Request) {
 	var payload storage.ReassignPayload
 	if err := decodeJSON(r, &payload); err != nil {
@@ -61,6 +151,11 @@ func (s *server) handleReassign(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id and old_user_id are required", s.logger)
 		return
 	}
+	explain, ok := s.explainParam(w, r)
+	if !ok {
+		return
+	}
+	payload.Explain = explain
 	pr, replacedBy, err := s.svc.Reassign(r.Context(), payload)
 	if err != nil {
 		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
@@ -68,3 +163,165 @@ func (s *server) handleReassign(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"pr": pr, "replaced_by": replacedBy}, s.logger)
 }
+
+func (s *server) handleAddReviewer(w http.ResponseWriter, r *http.Request) {
+	s.handleChangeReviewer(w, r, "add a reviewer to", s.svc.AddReviewer)
+}
+
+func (s *server) handleRemoveReviewer(w http.ResponseWriter, r *http.Request) {
+	s.handleChangeReviewer(w, r, "remove a reviewer from", s.svc.RemoveReviewer)
+}
+
+// handleChangeReviewer serves addReviewer and removeReviewer; action names the
+// operation in the PR_MERGED message, whose default text is about reassign.
+func (s *server) handleChangeReviewer(
+	w http.ResponseWriter,
+	r *http.Request,
+	action string,
+	change func(context.Context, storage.ReviewerPayload) (*storage.PullRequest, error),
+) {
+	var payload storage.ReviewerPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.PRID == "" || payload.UserID == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id and user_id are required", s.logger)
+		return
+	}
+	pr, err := change(r.Context(), payload)
+	if err != nil {
+		apiErr := mapErrorWithLog(s.logger, err)
+		if errors.Is(err, storage.ErrPRMerged) {
+			apiErr.Message = "cannot " + action + " merged PR"
+		}
+		writeJSONAPIError(w, apiErr, s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"pr": pr}, s.logger)
+}
+
+func (s *server) handleSubmitReview(w http.ResponseWriter, r *http.Request) {
+	var payload storage.SubmitReviewPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.PRID == "" || payload.UserID == "" || payload.Verdict == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST",
+			"pull_request_id, user_id and verdict are required", s.logger)
+		return
+	}
+	pr, err := s.svc.SubmitReview(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"pr": pr}, s.logger)
+}
+
+func (s *server) handleGetPR(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id is required", s.logger)
+		return
+	}
+	pr, err := s.svc.GetPR(r.Context(), prID)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"pr": pr}, s.logger)
+}
+
+func (s *server) handleListPRs(w http.ResponseWriter, r *http.Request) {
+	filter, ok := s.prFilterParams(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	filter.AuthorID = query.Get("author_id")
+	filter.ReviewerID = query.Get("reviewer_id")
+	filter.TeamName = query.Get("team_name")
+	page, err := s.svc.ListPRs(r.Context(), filter)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, page, s.logger)
+}
+
+// prFilterParams reads the status, date range, sort and paging parameters
+// shared by the PR lists; on a malformed value it writes the error response
+// and reports false.
+func (s *server) prFilterParams(w http.ResponseWriter, r *http.Request) (storage.PRFilter, bool) {
+	query := r.URL.Query()
+	filter := storage.PRFilter{
+		Status: query.Get("status"),
+		Sort:   query.Get("sort"),
+		Cursor: query.Get("cursor"),
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "limit must be a positive integer", s.logger)
+			return storage.PRFilter{}, false
+		}
+		filter.Limit = limit
+	}
+	for _, param := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"merged_from", &filter.MergedFrom},
+		{"merged_to", &filter.MergedTo},
+	} {
+		raw := query.Get(param.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", param.name+" must be an RFC 3339 timestamp", s.logger)
+			return storage.PRFilter{}, false
+		}
+		*param.dst = &t
+	}
+	return filter, true
+}
+
+func (s *server) handlePRHistory(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "pull_request_id is required", s.logger)
+		return
+	}
+	events, err := s.svc.PRHistory(r.Context(), prID)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"pull_request_id": prID,
+		"events":          events,
+	}, s.logger)
+}
+
+// explainParam reads the optional ?explain=true flag; on a malformed value it
+// writes the error response and reports false.
+func (s *server) explainParam(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	raw := r.URL.Query().Get("explain")
+	if raw == "" {
+		return false, true
+	}
+	explain, err := strconv.ParseBool(raw)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "explain must be a boolean", s.logger)
+		return false, false
+	}
+	return explain, true
+}
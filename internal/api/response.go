@@ -72,9 +72,148 @@ func mapErrorWithLog(logger *zap.SugaredLogger, err error) *apiError {
 			Code:       "NO_CANDIDATE",
 			Message:    "no active replacement candidate in team",
 		}
+	case errors.Is(err, storage.ErrInvalidReviewerCount):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_REVIEWER_COUNT",
+			Message:    "reviewer count is outside team bounds",
+		}
+	case errors.Is(err, storage.ErrInvalidAbsence):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_ABSENCE",
+			Message:    "absence must end after it starts",
+		}
+	case errors.Is(err, storage.ErrInvalidCapacity):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_CAPACITY",
+			Message:    "max_open_reviews must not be negative",
+		}
+	case errors.Is(err, storage.ErrInvalidPairingWindow):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_PAIRING_WINDOW",
+			Message:    "pairing_window must not be negative",
+		}
+	case errors.Is(err, storage.ErrInvalidTag):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_TAG",
+			Message:    "skills and required_tags must not contain empty tags",
+		}
+	case errors.Is(err, storage.ErrTagsUncovered):
+		return &apiError{
+			HTTPStatus: http.StatusConflict,
+			Code:       "TAGS_UNCOVERED",
+			Message:    "no eligible reviewers cover every required tag",
+		}
+	case errors.Is(err, storage.ErrInvalidRole):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_ROLE",
+			Message:    "role must be one of member, senior, lead",
+		}
+	case errors.Is(err, storage.ErrInvalidPolicy):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_POLICY",
+			Message:    "required_role must be senior or lead and required_role_count within [0, max_reviewers]",
+		}
+	case errors.Is(err, storage.ErrPolicyUnsatisfiable):
+		return &apiError{
+			HTTPStatus: http.StatusConflict,
+			Code:       "POLICY_UNSATISFIABLE",
+			Message:    "not enough eligible reviewers hold the required role",
+		}
+	case errors.Is(err, storage.ErrInvalidReviewers):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_REVIEWERS",
+			Message:    err.Error(),
+		}
+	case errors.Is(err, storage.ErrInvalidStatus):
+		return &apiError{
+			HTTPStatus: http.StatusConflict,
+			Code:       "INVALID_STATUS",
+			Message:    err.Error(),
+		}
+	case errors.Is(err, storage.ErrIneligibleReviewer):
+		return &apiError{
+			HTTPStatus: http.StatusConflict,
+			Code:       "INELIGIBLE_REVIEWER",
+			Message:    err.Error(),
+		}
+	case errors.Is(err, storage.ErrAlreadyAssigned):
+		return &apiError{
+			HTTPStatus: http.StatusConflict,
+			Code:       "ALREADY_ASSIGNED",
+			Message:    "new reviewer is already assigned to this PR",
+		}
+	case errors.Is(err, storage.ErrInvalidVerdict):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_VERDICT",
+			Message:    "verdict must be one of APPROVED, CHANGES_REQUESTED, COMMENTED",
+		}
+	case errors.Is(err, storage.ErrInvalidApprovals):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_APPROVALS",
+			Message:    "required_approvals must be within [0, max_reviewers]",
+		}
+	case errors.Is(err, storage.ErrNotEnoughApprovals):
+		return &apiError{
+			HTTPStatus: http.StatusConflict,
+			Code:       "NOT_ENOUGH_APPROVALS",
+			Message:    err.Error(),
+		}
+	case errors.Is(err, storage.ErrInvalidFilter):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_FILTER",
+			Message:    err.Error(),
+		}
+	case errors.Is(err, storage.ErrMemberOfOtherTeam):
+		return &apiError{
+			HTTPStatus: http.StatusConflict,
+			Code:       "MEMBER_OF_OTHER_TEAM",
+			Message:    err.Error(),
+		}
+	case errors.Is(err, storage.ErrNotTeamMember):
+		return &apiError{
+			HTTPStatus: http.StatusConflict,
+			Code:       "NOT_TEAM_MEMBER",
+			Message:    err.Error(),
+		}
+	case errors.Is(err, storage.ErrMemberHasOpenPRs):
+		return &apiError{
+			HTTPStatus: http.StatusConflict,
+			Code:       "MEMBER_HAS_OPEN_PRS",
+			Message:    err.Error(),
+		}
+	case errors.Is(err, storage.ErrTeamNotEmpty):
+		return &apiError{
+			HTTPStatus: http.StatusConflict,
+			Code:       "TEAM_NOT_EMPTY",
+			Message:    err.Error(),
+		}
+	case errors.Is(err, storage.ErrInvalidOwnership):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_OWNERSHIP",
+			Message:    err.Error(),
+		}
+	case errors.Is(err, storage.ErrInvalidFallback):
+		return &apiError{
+			HTTPStatus: http.StatusBadRequest,
+			Code:       "INVALID_FALLBACK",
+			Message:    "fallback teams must be distinct existing teams other than the team itself",
+		}
 	case errors.Is(err, storage.ErrUserNotFound),
 		errors.Is(err, storage.ErrPRNotFound),
-		errors.Is(err, storage.ErrTeamNotFound):
+		errors.Is(err, storage.ErrTeamNotFound),
+		errors.Is(err, storage.ErrAbsenceNotFound):
 		return &apiError{HTTPStatus: http.StatusNotFound, Code: "NOT_FOUND", Message: "resource not found"}
 	default:
 		if logger != nil {
@@ -25,15 +25,43 @@ func (s *server) Routes() http.Handler {
 	mux.HandleFunc("POST /team/add", s.handleAddTeam)
 	mux.HandleFunc("POST /team/deactivate", s.handleDeactivateTeam)
 	mux.HandleFunc("GET /team/get", s.handleGetTeam)
+	mux.HandleFunc("GET /team/list", s.handleListTeams)
+	mux.HandleFunc("POST /team/addMembers", s.handleAddMembers)
+	mux.HandleFunc("POST /team/removeMembers", s.handleRemoveMembers)
+	mux.HandleFunc("POST /team/rename", s.handleRenameTeam)
+	mux.HandleFunc("DELETE /team/delete", s.handleDeleteTeam)
+	mux.HandleFunc("POST /team/setSettings", s.handleSetTeamSettings)
+	mux.HandleFunc("POST /team/setOwnership", s.handleSetOwnership)
+	mux.HandleFunc("GET /team/getOwnership", s.handleGetOwnership)
 
 	// users
+	mux.HandleFunc("GET /users/get", s.handleGetUser)
+	mux.HandleFunc("PATCH /users/update", s.handleUpdateUser)
+	mux.HandleFunc("POST /users/moveTeam", s.handleMoveTeam)
+	mux.HandleFunc("DELETE /users/delete", s.handleDeleteUser)
 	mux.HandleFunc("POST /users/setIsActive", s.handleSetIsActive)
+	mux.HandleFunc("POST /users/setMaxOpenReviews", s.handleSetMaxOpenReviews)
+	mux.HandleFunc("POST /users/setRole", s.handleSetRole)
 	mux.HandleFunc("GET /users/getReview", s.handleGetReview)
+	mux.HandleFunc("POST /users/addAbsence", s.handleAddAbsence)
+	mux.HandleFunc("GET /users/getAbsences", s.handleGetAbsences)
+	mux.HandleFunc("POST /users/updateAbsence", s.handleUpdateAbsence)
+	mux.HandleFunc("POST /users/removeAbsence", s.handleRemoveAbsence)
 
 	// pull requests
 	mux.HandleFunc("POST /pullRequest/create", s.handleCreatePR)
+	mux.HandleFunc("POST /pullRequest/preview", s.handlePreviewPR)
+	mux.HandleFunc("GET /pullRequest/get", s.handleGetPR)
+	mux.HandleFunc("GET /pullRequest/list", s.handleListPRs)
 	mux.HandleFunc("POST /pullRequest/merge", s.handleMergePR)
+	mux.HandleFunc("POST /pullRequest/close", s.handleClosePR)
+	mux.HandleFunc("POST /pullRequest/ready", s.handleReadyPR)
+	mux.HandleFunc("POST /pullRequest/reopen", s.handleReopenPR)
 	mux.HandleFunc("POST /pullRequest/reassign", s.handleReassign)
+	mux.HandleFunc("POST /pullRequest/addReviewer", s.handleAddReviewer)
+	mux.HandleFunc("POST /pullRequest/removeReviewer", s.handleRemoveReviewer)
+	mux.HandleFunc("POST /pullRequest/submitReview", s.handleSubmitReview)
+	mux.HandleFunc("GET /pullRequest/history", s.handlePRHistory)
 
 	// stats
 	mux.HandleFunc("GET /stats", s.handleStats)
@@ -17,6 +17,16 @@ func (s *server) handleAddTeam(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name is required", s.logger)
 		return
 	}
+	if payload.ReviewerStrategy != "" && !storage.IsKnownStrategy(payload.ReviewerStrategy) {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "unknown reviewer_strategy", s.logger)
+		return
+	}
+	for _, m := range payload.Members {
+		if m.ReviewWeight < 0 {
+			writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "review_weight must not be negative", s.logger)
+			return
+		}
+	}
 	team, err := s.svc.AddTeam(r.Context(), payload)
 	if err != nil {
 		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
@@ -58,3 +68,154 @@ func (s *server) handleGetTeam(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, team, s.logger)
 }
+
+func (s *server) handleListTeams(w http.ResponseWriter, r *http.Request) {
+	teams, err := s.svc.ListTeams(r.Context())
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"teams": teams}, s.logger)
+}
+
+func (s *server) handleAddMembers(w http.ResponseWriter, r *http.Request) {
+	var payload storage.MembersPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.TeamName == "" || len(payload.Members) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name and members are required", s.logger)
+		return
+	}
+	for _, m := range payload.Members {
+		if m.UserID == "" {
+			writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id is required for every member", s.logger)
+			return
+		}
+		if m.ReviewWeight < 0 {
+			writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "review_weight must not be negative", s.logger)
+			return
+		}
+	}
+	team, err := s.svc.AddTeamMembers(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"team": team}, s.logger)
+}
+
+func (s *server) handleRemoveMembers(w http.ResponseWriter, r *http.Request) {
+	var payload storage.RemoveMembersPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.TeamName == "" || len(payload.UserIDs) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name and user_ids are required", s.logger)
+		return
+	}
+	removed, err := s.svc.RemoveTeamMembers(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, removed, s.logger)
+}
+
+func (s *server) handleRenameTeam(w http.ResponseWriter, r *http.Request) {
+	var payload storage.RenameTeamPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.TeamName == "" || payload.NewName == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name and new_team_name are required", s.logger)
+		return
+	}
+	team, err := s.svc.RenameTeam(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"team": team}, s.logger)
+}
+
+func (s *server) handleDeleteTeam(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name is required", s.logger)
+		return
+	}
+	deleted, err := s.svc.DeleteTeam(r.Context(), teamName)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"team_name":       deleted.TeamName,
+		"status":          "deleted",
+		"ownership_rules": deleted.OwnershipRules,
+	}, s.logger)
+}
+
+func (s *server) handleSetTeamSettings(w http.ResponseWriter, r *http.Request) {
+	var payload storage.TeamSettingsPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.TeamName == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name is required", s.logger)
+		return
+	}
+	if payload.ReviewerStrategy != nil && *payload.ReviewerStrategy != "" &&
+		!storage.IsKnownStrategy(*payload.ReviewerStrategy) {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "unknown reviewer_strategy", s.logger)
+		return
+	}
+	settings, err := s.svc.UpdateTeamSettings(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"settings": settings}, s.logger)
+}
+
+func (s *server) handleSetOwnership(w http.ResponseWriter, r *http.Request) {
+	var payload storage.OwnershipPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.TeamName == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name is required", s.logger)
+		return
+	}
+	ownership, err := s.svc.SetOwnership(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ownership": ownership}, s.logger)
+}
+
+func (s *server) handleGetOwnership(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "team_name is required", s.logger)
+		return
+	}
+	ownership, err := s.svc.TeamOwnership(r.Context(), teamName)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ownership": ownership}, s.logger)
+}
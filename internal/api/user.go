@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 
 	"prreviewer/internal/storage"
 )
@@ -17,7 +18,49 @@ func (s *server) handleSetIsActive(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id is required", s.logger)
 		return
 	}
-	user, err := s.svc.SetUserActive(r.Context(), payload)
+	user, summary, err := s.svc.SetUserActive(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	resp := map[string]any{"user": user}
+	if summary != nil {
+		resp["reassignment"] = summary
+	}
+	writeJSON(w, http.StatusOK, resp, s.logger)
+}
+
+func (s *server) handleSetMaxOpenReviews(w http.ResponseWriter, r *http.Request) {
+	var payload storage.SetMaxOpenReviewsPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.UserID == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id is required", s.logger)
+		return
+	}
+	user, err := s.svc.SetMaxOpenReviews(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"user": user}, s.logger)
+}
+
+func (s *server) handleSetRole(w http.ResponseWriter, r *http.Request) {
+	var payload storage.SetRolePayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.UserID == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id is required", s.logger)
+		return
+	}
+	user, err := s.svc.SetUserRole(r.Context(), payload)
 	if err != nil {
 		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
 		return
@@ -31,13 +74,111 @@ func (s *server) handleGetReview(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id is required", s.logger)
 		return
 	}
-	prs, err := s.svc.UserReviews(r.Context(), userID)
+	filter, ok := s.prFilterParams(w, r)
+	if !ok {
+		return
+	}
+	full := false
+	if raw := r.URL.Query().Get("full"); raw != "" {
+		var err error
+		if full, err = strconv.ParseBool(raw); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "full must be a boolean", s.logger)
+			return
+		}
+	}
+	page, err := s.svc.UserReviews(r.Context(), userID, filter)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	resp := map[string]any{"user_id": userID}
+	if full {
+		resp["pull_requests"] = page.PullRequests
+	} else {
+		short := make([]storage.PullRequestShort, 0, len(page.PullRequests))
+		for _, pr := range page.PullRequests {
+			short = append(short, pr.Short())
+		}
+		resp["pull_requests"] = short
+	}
+	if page.NextCursor != "" {
+		resp["next_cursor"] = page.NextCursor
+	}
+	writeJSON(w, http.StatusOK, resp, s.logger)
+}
+
+func (s *server) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id is required", s.logger)
+		return
+	}
+	user, err := s.svc.GetUser(r.Context(), userID)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"user": user}, s.logger)
+}
+
+func (s *server) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	var payload storage.UpdateUserPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.UserID == "" || payload.Username == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id and username are required", s.logger)
+		return
+	}
+	user, err := s.svc.UpdateUser(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"user": user}, s.logger)
+}
+
+func (s *server) handleMoveTeam(w http.ResponseWriter, r *http.Request) {
+	var payload storage.MoveTeamPayload
+	if err := decodeJSON(r, &payload); err != nil {
+		s.logger.Warnw("invalid json", "err", err)
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), s.logger)
+		return
+	}
+	if payload.UserID == "" || payload.TeamName == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id and team_name are required", s.logger)
+		return
+	}
+	user, summary, err := s.svc.MoveUserTeam(r.Context(), payload)
+	if err != nil {
+		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
+		return
+	}
+	resp := map[string]any{"user": user}
+	if summary != nil {
+		resp["reassignment"] = summary
+	}
+	writeJSON(w, http.StatusOK, resp, s.logger)
+}
+
+func (s *server) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "user_id is required", s.logger)
+		return
+	}
+	deleted, err := s.svc.DeleteUser(r.Context(), userID)
 	if err != nil {
 		writeJSONAPIError(w, mapErrorWithLog(s.logger, err), s.logger)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{
-		"user_id":       userID,
-		"pull_requests": prs,
+		"user_id":         deleted.UserID,
+		"status":          "deleted",
+		"reassignment":    deleted.Reassignment,
+		"closed_prs":      deleted.ClosedPRs,
+		"ownership_rules": deleted.OwnershipRules,
 	}, s.logger)
 }
@@ -15,13 +15,44 @@ type Service struct {
 type Store interface {
 	AddTeam(ctx context.Context, payload storage.TeamPayload) (storage.TeamPayload, error)
 	GetTeam(ctx context.Context, teamName string) (storage.TeamPayload, error)
-	SetUserActive(ctx context.Context, payload storage.SetActivePayload) (*storage.User, error)
+	ListTeams(ctx context.Context) ([]storage.TeamSummary, error)
+	AddTeamMembers(ctx context.Context, payload storage.MembersPayload) (storage.TeamPayload, error)
+	RemoveTeamMembers(ctx context.Context, payload storage.RemoveMembersPayload) (*storage.RemovedMembers, error)
+	RenameTeam(ctx context.Context, payload storage.RenameTeamPayload) (storage.TeamPayload, error)
+	DeleteTeam(ctx context.Context, teamName string) (*storage.DeletedTeam, error)
+	SetUserActive(
+		ctx context.Context,
+		payload storage.SetActivePayload,
+	) (*storage.User, *storage.ReassignmentSummary, error)
 	CreatePR(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error)
+	PreviewPR(ctx context.Context, payload storage.CreatePRPayload) (*storage.AssignmentPreview, error)
 	MergePR(ctx context.Context, id string) (*storage.PullRequest, error)
+	ClosePR(ctx context.Context, id string) (*storage.PullRequest, error)
+	GetPR(ctx context.Context, id string) (*storage.PullRequest, error)
+	ListPRs(ctx context.Context, filter storage.PRFilter) (*storage.PRPage, error)
+	SubmitReview(ctx context.Context, payload storage.SubmitReviewPayload) (*storage.PullRequest, error)
+	ReadyPR(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error)
+	ReopenPR(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error)
 	Reassign(ctx context.Context, payload storage.ReassignPayload) (*storage.PullRequest, string, error)
-	UserReviews(ctx context.Context, userID string) ([]storage.PullRequestShort, error)
+	AddReviewer(ctx context.Context, payload storage.ReviewerPayload) (*storage.PullRequest, error)
+	RemoveReviewer(ctx context.Context, payload storage.ReviewerPayload) (*storage.PullRequest, error)
+	UserReviews(ctx context.Context, userID string, filter storage.PRFilter) (*storage.PRPage, error)
 	Stats(ctx context.Context) (*storage.Stats, error)
 	MassDeactivate(ctx context.Context, teamName string) error
+	UpdateTeamSettings(ctx context.Context, payload storage.TeamSettingsPayload) (*storage.TeamSettings, error)
+	SetOwnership(ctx context.Context, payload storage.OwnershipPayload) (*storage.Ownership, error)
+	TeamOwnership(ctx context.Context, teamName string) (*storage.Ownership, error)
+	PRHistory(ctx context.Context, prID string) ([]storage.AssignmentEvent, error)
+	SetMaxOpenReviews(ctx context.Context, payload storage.SetMaxOpenReviewsPayload) (*storage.User, error)
+	SetUserRole(ctx context.Context, payload storage.SetRolePayload) (*storage.User, error)
+	GetUser(ctx context.Context, userID string) (*storage.User, error)
+	UpdateUser(ctx context.Context, payload storage.UpdateUserPayload) (*storage.User, error)
+	MoveUserTeam(ctx context.Context, payload storage.MoveTeamPayload) (*storage.User, *storage.ReassignmentSummary, error)
+	DeleteUser(ctx context.Context, userID string) (*storage.DeletedUser, error)
+	AddAbsence(ctx context.Context, payload storage.AbsencePayload) (*storage.Absence, error)
+	UserAbsences(ctx context.Context, userID string) ([]storage.Absence, error)
+	UpdateAbsence(ctx context.Context, payload storage.AbsenceUpdatePayload) (*storage.Absence, error)
+	RemoveAbsence(ctx context.Context, id int64) error
 }
 
 func New(store Store) *Service {
@@ -40,24 +71,151 @@ func (s *Service) GetTeam(ctx context.Context, teamName string) (storage.TeamPay
 	return s.store.GetTeam(ctx, teamName)
 }
 
-func (s *Service) SetUserActive(ctx context.Context, payload storage.SetActivePayload) (*storage.User, error) {
+func (s *Service) ListTeams(ctx context.Context) ([]storage.TeamSummary, error) {
+	return s.store.ListTeams(ctx)
+}
+
+func (s *Service) AddTeamMembers(ctx context.Context, payload storage.MembersPayload) (storage.TeamPayload, error) {
+	return s.store.AddTeamMembers(ctx, payload)
+}
+
+func (s *Service) RemoveTeamMembers(
+	ctx context.Context,
+	payload storage.RemoveMembersPayload,
+) (*storage.RemovedMembers, error) {
+	return s.store.RemoveTeamMembers(ctx, payload)
+}
+
+func (s *Service) RenameTeam(ctx context.Context, payload storage.RenameTeamPayload) (storage.TeamPayload, error) {
+	return s.store.RenameTeam(ctx, payload)
+}
+
+func (s *Service) DeleteTeam(ctx context.Context, teamName string) (*storage.DeletedTeam, error) {
+	return s.store.DeleteTeam(ctx, teamName)
+}
+
+func (s *Service) UpdateTeamSettings(
+	ctx context.Context,
+	payload storage.TeamSettingsPayload,
+) (*storage.TeamSettings, error) {
+	return s.store.UpdateTeamSettings(ctx, payload)
+}
+
+func (s *Service) SetOwnership(ctx context.Context, payload storage.OwnershipPayload) (*storage.Ownership, error) {
+	return s.store.SetOwnership(ctx, payload)
+}
+
+func (s *Service) TeamOwnership(ctx context.Context, teamName string) (*storage.Ownership, error) {
+	return s.store.TeamOwnership(ctx, teamName)
+}
+
+func (s *Service) SetUserActive(
+	ctx context.Context,
+	payload storage.SetActivePayload,
+) (*storage.User, *storage.ReassignmentSummary, error) {
 	return s.store.SetUserActive(ctx, payload)
 }
 
+func (s *Service) SetMaxOpenReviews(
+	ctx context.Context,
+	payload storage.SetMaxOpenReviewsPayload,
+) (*storage.User, error) {
+	return s.store.SetMaxOpenReviews(ctx, payload)
+}
+
+func (s *Service) SetUserRole(ctx context.Context, payload storage.SetRolePayload) (*storage.User, error) {
+	return s.store.SetUserRole(ctx, payload)
+}
+
+func (s *Service) GetUser(ctx context.Context, userID string) (*storage.User, error) {
+	return s.store.GetUser(ctx, userID)
+}
+
+func (s *Service) UpdateUser(ctx context.Context, payload storage.UpdateUserPayload) (*storage.User, error) {
+	return s.store.UpdateUser(ctx, payload)
+}
+
+func (s *Service) MoveUserTeam(
+	ctx context.Context,
+	payload storage.MoveTeamPayload,
+) (*storage.User, *storage.ReassignmentSummary, error) {
+	return s.store.MoveUserTeam(ctx, payload)
+}
+
+func (s *Service) DeleteUser(ctx context.Context, userID string) (*storage.DeletedUser, error) {
+	return s.store.DeleteUser(ctx, userID)
+}
+
+func (s *Service) AddAbsence(ctx context.Context, payload storage.AbsencePayload) (*storage.Absence, error) {
+	return s.store.AddAbsence(ctx, payload)
+}
+
+func (s *Service) UserAbsences(ctx context.Context, userID string) ([]storage.Absence, error) {
+	return s.store.UserAbsences(ctx, userID)
+}
+
+func (s *Service) UpdateAbsence(ctx context.Context, payload storage.AbsenceUpdatePayload) (*storage.Absence, error) {
+	return s.store.UpdateAbsence(ctx, payload)
+}
+
+func (s *Service) RemoveAbsence(ctx context.Context, id int64) error {
+	return s.store.RemoveAbsence(ctx, id)
+}
+
 func (s *Service) CreatePR(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error) {
 	return s.store.CreatePR(ctx, payload)
 }
 
+func (s *Service) PreviewPR(ctx context.Context, payload storage.CreatePRPayload) (*storage.AssignmentPreview, error) {
+	return s.store.PreviewPR(ctx, payload)
+}
+
 func (s *Service) MergePR(ctx context.Context, id string) (*storage.PullRequest, error) {
 	return s.store.MergePR(ctx, id)
 }
 
+func (s *Service) SubmitReview(ctx context.Context, payload storage.SubmitReviewPayload) (*storage.PullRequest, error) {
+	return s.store.SubmitReview(ctx, payload)
+}
+
+func (s *Service) GetPR(ctx context.Context, id string) (*storage.PullRequest, error) {
+	return s.store.GetPR(ctx, id)
+}
+
+func (s *Service) ListPRs(ctx context.Context, filter storage.PRFilter) (*storage.PRPage, error) {
+	return s.store.ListPRs(ctx, filter)
+}
+
+func (s *Service) ClosePR(ctx context.Context, id string) (*storage.PullRequest, error) {
+	return s.store.ClosePR(ctx, id)
+}
+
+func (s *Service) ReadyPR(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error) {
+	return s.store.ReadyPR(ctx, payload)
+}
+
+func (s *Service) ReopenPR(ctx context.Context, payload storage.CreatePRPayload) (*storage.PullRequest, error) {
+	return s.store.ReopenPR(ctx, payload)
+}
+
 func (s *Service) Reassign(ctx context.Context, payload storage.ReassignPayload) (*storage.PullRequest, string, error) {
 	return s.store.Reassign(ctx, payload)
 }
 
-func (s *Service) UserReviews(ctx context.Context, userID string) ([]storage.PullRequestShort, error) {
-	return s.store.UserReviews(ctx, userID)
+func (s *Service) AddReviewer(ctx context.Context, payload storage.ReviewerPayload) (*storage.PullRequest, error) {
+	return s.store.AddReviewer(ctx, payload)
+}
+
+func (s *Service) RemoveReviewer(ctx context.Context, payload storage.ReviewerPayload) (*storage.PullRequest, error) {
+	return s.store.RemoveReviewer(ctx, payload)
+}
+
+func (s *Service) PRHistory(ctx context.Context, prID string) ([]storage.AssignmentEvent, error) {
+	return s.store.PRHistory(ctx, prID)
+}
+
+func (s *Service) UserReviews(ctx context.Context, userID string, filter storage.PRFilter) (*storage.PRPage, error) {
+	return s.store.UserReviews(ctx, userID, filter)
 }
 
 func (s *Service) Stats(ctx context.Context) (*storage.Stats, error) {
@@ -20,23 +20,79 @@ func (f *fakeStore) GetTeam(context.Context, string) (storage.TeamPayload, error
 	return storage.TeamPayload{}, f.err
 }
 
-func (f *fakeStore) SetUserActive(context.Context, storage.SetActivePayload) (*storage.User, error) {
+func (f *fakeStore) ListTeams(context.Context) ([]storage.TeamSummary, error) {
 	return nil, f.err
 }
 
+func (f *fakeStore) AddTeamMembers(context.Context, storage.MembersPayload) (storage.TeamPayload, error) {
+	return storage.TeamPayload{}, f.err
+}
+
+func (f *fakeStore) RemoveTeamMembers(context.Context, storage.RemoveMembersPayload) (*storage.RemovedMembers, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) RenameTeam(context.Context, storage.RenameTeamPayload) (storage.TeamPayload, error) {
+	return storage.TeamPayload{}, f.err
+}
+
+func (f *fakeStore) DeleteTeam(context.Context, string) (*storage.DeletedTeam, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) SetUserActive(context.Context, storage.SetActivePayload) (*storage.User, *storage.ReassignmentSummary, error) {
+	return nil, nil, f.err
+}
+
 func (f *fakeStore) CreatePR(context.Context, storage.CreatePRPayload) (*storage.PullRequest, error) {
 	return nil, f.err
 }
 
+func (f *fakeStore) PreviewPR(context.Context, storage.CreatePRPayload) (*storage.AssignmentPreview, error) {
+	return nil, f.err
+}
+
 func (f *fakeStore) MergePR(context.Context, string) (*storage.PullRequest, error) {
 	return nil, f.err
 }
 
+func (f *fakeStore) SubmitReview(context.Context, storage.SubmitReviewPayload) (*storage.PullRequest, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) GetPR(context.Context, string) (*storage.PullRequest, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) ListPRs(context.Context, storage.PRFilter) (*storage.PRPage, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) ClosePR(context.Context, string) (*storage.PullRequest, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) ReadyPR(context.Context, storage.CreatePRPayload) (*storage.PullRequest, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) ReopenPR(context.Context, storage.CreatePRPayload) (*storage.PullRequest, error) {
+	return nil, f.err
+}
+
 func (f *fakeStore) Reassign(context.Context, storage.ReassignPayload) (*storage.PullRequest, string, error) {
 	return nil, "", f.err
 }
 
-func (f *fakeStore) UserReviews(context.Context, string) ([]storage.PullRequestShort, error) {
+func (f *fakeStore) AddReviewer(context.Context, storage.ReviewerPayload) (*storage.PullRequest, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) RemoveReviewer(context.Context, storage.ReviewerPayload) (*storage.PullRequest, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) UserReviews(context.Context, string, storage.PRFilter) (*storage.PRPage, error) {
 	return nil, f.err
 }
 
@@ -48,6 +104,62 @@ func (f *fakeStore) MassDeactivate(context.Context, string) error {
 	return f.err
 }
 
+func (f *fakeStore) UpdateTeamSettings(context.Context, storage.TeamSettingsPayload) (*storage.TeamSettings, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) SetOwnership(context.Context, storage.OwnershipPayload) (*storage.Ownership, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) TeamOwnership(context.Context, string) (*storage.Ownership, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) PRHistory(context.Context, string) ([]storage.AssignmentEvent, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) SetMaxOpenReviews(context.Context, storage.SetMaxOpenReviewsPayload) (*storage.User, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) SetUserRole(context.Context, storage.SetRolePayload) (*storage.User, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) GetUser(context.Context, string) (*storage.User, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) UpdateUser(context.Context, storage.UpdateUserPayload) (*storage.User, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) MoveUserTeam(context.Context, storage.MoveTeamPayload) (*storage.User, *storage.ReassignmentSummary, error) {
+	return nil, nil, f.err
+}
+
+func (f *fakeStore) DeleteUser(context.Context, string) (*storage.DeletedUser, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) AddAbsence(context.Context, storage.AbsencePayload) (*storage.Absence, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) UserAbsences(context.Context, string) ([]storage.Absence, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) UpdateAbsence(context.Context, storage.AbsenceUpdatePayload) (*storage.Absence, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) RemoveAbsence(context.Context, int64) error {
+	return f.err
+}
+
 func TestServicePropagatesError(t *testing.T) {
 	wantErr := errors.New("boom")
 	s := New(&fakeStore{err: wantErr})
@@ -62,22 +174,106 @@ func TestServicePropagatesError(t *testing.T) {
 	if _, err := s.GetTeam(ctx, "team"); !errors.Is(err, wantErr) {
 		t.Fatalf("GetTeam err = %v, want %v", err, wantErr)
 	}
-	if _, err := s.SetUserActive(ctx, storage.SetActivePayload{}); !errors.Is(err, wantErr) {
+	if _, err := s.ListTeams(ctx); !errors.Is(err, wantErr) {
+		t.Fatalf("ListTeams err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.AddTeamMembers(ctx, storage.MembersPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("AddTeamMembers err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.RemoveTeamMembers(ctx, storage.RemoveMembersPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("RemoveTeamMembers err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.RenameTeam(ctx, storage.RenameTeamPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("RenameTeam err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.DeleteTeam(ctx, "team"); !errors.Is(err, wantErr) {
+		t.Fatalf("DeleteTeam err = %v, want %v", err, wantErr)
+	}
+	if _, _, err := s.SetUserActive(ctx, storage.SetActivePayload{}); !errors.Is(err, wantErr) {
 		t.Fatalf("SetUserActive err = %v, want %v", err, wantErr)
 	}
 	if _, err := s.CreatePR(ctx, storage.CreatePRPayload{}); !errors.Is(err, wantErr) {
 		t.Fatalf("CreatePR err = %v, want %v", err, wantErr)
 	}
+	if _, err := s.PreviewPR(ctx, storage.CreatePRPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("PreviewPR err = %v, want %v", err, wantErr)
+	}
 	if _, err := s.MergePR(ctx, "pr"); !errors.Is(err, wantErr) {
 		t.Fatalf("MergePR err = %v, want %v", err, wantErr)
 	}
+	if _, err := s.SubmitReview(ctx, storage.SubmitReviewPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("SubmitReview err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.GetPR(ctx, "pr"); !errors.Is(err, wantErr) {
+		t.Fatalf("GetPR err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.ListPRs(ctx, storage.PRFilter{}); !errors.Is(err, wantErr) {
+		t.Fatalf("ListPRs err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.ClosePR(ctx, "pr"); !errors.Is(err, wantErr) {
+		t.Fatalf("ClosePR err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.ReadyPR(ctx, storage.CreatePRPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("ReadyPR err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.ReopenPR(ctx, storage.CreatePRPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("ReopenPR err = %v, want %v", err, wantErr)
+	}
 	if _, _, err := s.Reassign(ctx, storage.ReassignPayload{}); !errors.Is(err, wantErr) {
 		t.Fatalf("Reassign err = %v, want %v", err, wantErr)
 	}
-	if _, err := s.UserReviews(ctx, "u"); !errors.Is(err, wantErr) {
+	if _, err := s.AddReviewer(ctx, storage.ReviewerPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("AddReviewer err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.RemoveReviewer(ctx, storage.ReviewerPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("RemoveReviewer err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.UserReviews(ctx, "u", storage.PRFilter{}); !errors.Is(err, wantErr) {
 		t.Fatalf("UserReviews err = %v, want %v", err, wantErr)
 	}
 	if _, err := s.Stats(ctx); !errors.Is(err, wantErr) {
 		t.Fatalf("Stats err = %v, want %v", err, wantErr)
 	}
+	if _, err := s.UpdateTeamSettings(ctx, storage.TeamSettingsPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("UpdateTeamSettings err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.SetOwnership(ctx, storage.OwnershipPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("SetOwnership err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.TeamOwnership(ctx, "team"); !errors.Is(err, wantErr) {
+		t.Fatalf("TeamOwnership err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.PRHistory(ctx, "pr"); !errors.Is(err, wantErr) {
+		t.Fatalf("PRHistory err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.SetMaxOpenReviews(ctx, storage.SetMaxOpenReviewsPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("SetMaxOpenReviews err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.SetUserRole(ctx, storage.SetRolePayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("SetUserRole err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.GetUser(ctx, "u"); !errors.Is(err, wantErr) {
+		t.Fatalf("GetUser err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.UpdateUser(ctx, storage.UpdateUserPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("UpdateUser err = %v, want %v", err, wantErr)
+	}
+	if _, _, err := s.MoveUserTeam(ctx, storage.MoveTeamPayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("MoveUserTeam err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.DeleteUser(ctx, "u"); !errors.Is(err, wantErr) {
+		t.Fatalf("DeleteUser err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.AddAbsence(ctx, storage.AbsencePayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("AddAbsence err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.UserAbsences(ctx, "u"); !errors.Is(err, wantErr) {
+		t.Fatalf("UserAbsences err = %v, want %v", err, wantErr)
+	}
+	if _, err := s.UpdateAbsence(ctx, storage.AbsenceUpdatePayload{}); !errors.Is(err, wantErr) {
+		t.Fatalf("UpdateAbsence err = %v, want %v", err, wantErr)
+	}
+	if err := s.RemoveAbsence(ctx, 1); !errors.Is(err, wantErr) {
+		t.Fatalf("RemoveAbsence err = %v, want %v", err, wantErr)
+	}
 }
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Absence is an out-of-office window. The user is skipped by reviewer
+// selection while the current time is within [StartsAt, EndsAt).
+type Absence struct {
+	ID       int64     `json:"absence_id"`
+	UserID   string    `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+type AbsencePayload struct {
+	UserID   string    `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+// AbsenceUpdatePayload is a partial update: nil fields keep their current value.
+type AbsenceUpdatePayload struct {
+	ID       int64      `json:"absence_id"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+	Reason   *string    `json:"reason,omitempty"`
+}
+
+func (s *Store) AddAbsence(ctx context.Context, payload AbsencePayload) (*Absence, error) {
+	if !payload.EndsAt.After(payload.StartsAt) {
+		return nil, ErrInvalidAbsence
+	}
+	a := Absence{
+		UserID:   payload.UserID,
+		StartsAt: payload.StartsAt,
+		EndsAt:   payload.EndsAt,
+		Reason:   payload.Reason,
+	}
+	err := s.db.QueryRowContext(ctx, `
+INSERT INTO user_absences(user_id, starts_at, ends_at, reason)
+SELECT user_id, $2, $3, $4 FROM users WHERE user_id=$1
+RETURNING id
+`, payload.UserID, payload.StartsAt, payload.EndsAt, payload.Reason).Scan(&a.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+// UserAbsences lists all absence windows of the user, earliest first.
+func (s *Store) UserAbsences(ctx context.Context, userID string) ([]Absence, error) {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)`, userID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	rows, err := s.db.QueryContext(ctx, `
+SELECT id, user_id, starts_at, ends_at, reason
+FROM user_absences
+WHERE user_id=$1
+ORDER BY starts_at, id
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	absences := make([]Absence, 0)
+	for rows.Next() {
+		var a Absence
+		if err := rows.Scan(&a.ID, &a.UserID, &a.StartsAt, &a.EndsAt, &a.Reason); err != nil {
+			return nil, err
+		}
+		absences = append(absences, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return absences, nil
+}
+
+func (s *Store) UpdateAbsence(ctx context.Context, payload AbsenceUpdatePayload) (*Absence, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	var a Absence
+	err = tx.QueryRowContext(ctx, `
+SELECT id, user_id, starts_at, ends_at, reason
+FROM user_absences
+WHERE id=$1
+FOR UPDATE
+`, payload.ID).Scan(&a.ID, &a.UserID, &a.StartsAt, &a.EndsAt, &a.Reason)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAbsenceNotFound
+		}
+		return nil, err
+	}
+	if payload.StartsAt != nil {
+		a.StartsAt = *payload.StartsAt
+	}
+	if payload.EndsAt != nil {
+		a.EndsAt = *payload.EndsAt
+	}
+	if payload.Reason != nil {
+		a.Reason = *payload.Reason
+	}
+	if !a.EndsAt.After(a.StartsAt) {
+		return nil, ErrInvalidAbsence
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE user_absences
+SET starts_at = $2, ends_at = $3, reason = $4
+WHERE id = $1
+`, a.ID, a.StartsAt, a.EndsAt, a.Reason); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *Store) RemoveAbsence(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM user_absences WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAbsenceNotFound
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestAddAbsenceSuccess(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	start := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(14 * 24 * time.Hour)
+	mock.ExpectQuery(`INSERT INTO user_absences`).
+		WithArgs("u1", start, end, "vacation").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+
+	a, err := store.AddAbsence(context.Background(), AbsencePayload{
+		UserID: "u1", StartsAt: start, EndsAt: end, Reason: "vacation",
+	})
+	if err != nil {
+		t.Fatalf("AddAbsence error: %v", err)
+	}
+	if a.ID != 7 || a.UserID != "u1" || !a.EndsAt.Equal(end) {
+		t.Fatalf("unexpected absence: %+v", a)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestAddAbsenceUserNotFound(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	start := time.Now()
+	mock.ExpectQuery(`INSERT INTO user_absences`).
+		WithArgs("u404", start, start.Add(time.Hour), "").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := store.AddAbsence(context.Background(), AbsencePayload{
+		UserID: "u404", StartsAt: start, EndsAt: start.Add(time.Hour),
+	})
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestAddAbsenceRejectsEmptyWindow(t *testing.T) {
+	store, _, cleanup := newMockStore(t)
+	defer cleanup()
+
+	start := time.Now()
+	_, err := store.AddAbsence(context.Background(), AbsencePayload{UserID: "u1", StartsAt: start, EndsAt: start})
+	if !errors.Is(err, ErrInvalidAbsence) {
+		t.Fatalf("expected ErrInvalidAbsence, got %v", err)
+	}
+}
+
+func TestUserAbsencesList(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	start := time.Now()
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM users WHERE user_id=`).
+		WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`FROM user_absences`).
+		WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "starts_at", "ends_at", "reason"}).
+			AddRow(int64(1), "u1", start, start.Add(time.Hour), "dentist"))
+
+	list, err := store.UserAbsences(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("UserAbsences error: %v", err)
+	}
+	if len(list) != 1 || list[0].Reason != "dentist" {
+		t.Fatalf("unexpected absences: %+v", list)
+	}
+}
+
+func TestUpdateAbsenceValidatesMergedWindow(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	start := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM user_absences\s+WHERE id=\$1\s+FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "starts_at", "ends_at", "reason"}).
+			AddRow(int64(1), "u1", start, start.Add(time.Hour), ""))
+	mock.ExpectRollback()
+
+	earlier := start.Add(-time.Hour)
+	_, err := store.UpdateAbsence(context.Background(), AbsenceUpdatePayload{ID: 1, EndsAt: &earlier})
+	if !errors.Is(err, ErrInvalidAbsence) {
+		t.Fatalf("expected ErrInvalidAbsence, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestUpdateAbsenceSuccess(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	start := time.Now()
+	later := start.Add(48 * time.Hour)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM user_absences\s+WHERE id=\$1\s+FOR UPDATE`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "starts_at", "ends_at", "reason"}).
+			AddRow(int64(1), "u1", start, start.Add(time.Hour), "sick"))
+	mock.ExpectExec(`UPDATE user_absences`).
+		WithArgs(int64(1), start, later, "sick").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	a, err := store.UpdateAbsence(context.Background(), AbsenceUpdatePayload{ID: 1, EndsAt: &later})
+	if err != nil {
+		t.Fatalf("UpdateAbsence error: %v", err)
+	}
+	if !a.EndsAt.Equal(later) || a.Reason != "sick" {
+		t.Fatalf("unexpected absence: %+v", a)
+	}
+}
+
+func TestRemoveAbsenceNotFound(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectExec(`DELETE FROM user_absences`).
+		WithArgs(int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := store.RemoveAbsence(context.Background(), 9); !errors.Is(err, ErrAbsenceNotFound) {
+		t.Fatalf("expected ErrAbsenceNotFound, got %v", err)
+	}
+}
+
+func TestPickCandidatesSkipsAbsentUsers(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	expectTeamSettings(mock, "backend")
+	mock.ExpectQuery(`EXISTS \(\s+SELECT 1 FROM user_absences ua`).
+		WithArgs("backend", StatusOpen).
+		WillReturnRows(sqlmock.NewRows(memberColumns).
+			AddRow("away", true, 1, 0, true, nil, 0, RoleMember).
+			AddRow("here", true, 1, 0, false, nil, 0, RoleMember))
+
+	cands, err := store.pickCandidates(context.Background(), store.db, "backend", "", nil, 2)
+	if err != nil {
+		t.Fatalf("pickCandidates error: %v", err)
+	}
+	if len(cands) != 1 || cands[0] != "here" {
+		t.Fatalf("expected absent user to be skipped, got %v", cands)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestEligibleCandidatesCapacity(t *testing.T) {
+	members := []teamMember{
+		{Candidate: Candidate{UserID: "free", OpenReviews: 0}, active: true},
+		{Candidate: Candidate{UserID: "busy", OpenReviews: 3}, active: true},
+		{Candidate: Candidate{UserID: "capped", OpenReviews: 1, MaxOpenReviews: 1}, active: true},
+		{Candidate: Candidate{UserID: "raised", OpenReviews: 3, MaxOpenReviews: 5}, active: true},
+	}
+	got, _ := eligibleCandidates(members, "", nil, 3)
+	if want := []string{"free", "raised"}; !reflect.DeepEqual(candidateIDs(got), want) {
+		t.Fatalf("eligible = %v, want %v", candidateIDs(got), want)
+	}
+	got, _ = eligibleCandidates(members, "", nil, 0)
+	if want := []string{"free", "busy", "raised"}; !reflect.DeepEqual(candidateIDs(got), want) {
+		t.Fatalf("eligible without team default = %v, want %v", candidateIDs(got), want)
+	}
+}
+
+func TestPickCandidatesSkipsUsersAtCapacity(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectQuery(teamSettingsPattern).
+		WithArgs(teamBackend).
+		WillReturnRows(sqlmock.NewRows([]string{"reviewer_strategy", "min_reviewers", "max_reviewers", "default_max_open_reviews", "pairing_window", "required_role", "required_role_count", "required_approvals"}).
+			AddRow(nil, DefaultMinReviewers, DefaultMaxReviewers, 2, 0, "", 0, 0))
+	mock.ExpectQuery(membersPattern).
+		WithArgs(teamBackend, StatusOpen).
+		WillReturnRows(sqlmock.NewRows(memberColumns).
+			AddRow("oncall", true, 1, 1, false, nil, 1, RoleMember).
+			AddRow("busy", true, 1, 0, false, nil, 2, RoleMember).
+			AddRow("u1", true, 1, 0, false, nil, 1, RoleMember))
+
+	cands, err := store.pickCandidates(context.Background(), store.db, teamBackend, authorID, nil, 2)
+	if err != nil {
+		t.Fatalf("pickCandidates error: %v", err)
+	}
+	if !reflect.DeepEqual(cands, []string{"u1"}) {
+		t.Fatalf("expected only u1 below capacity, got %v", cands)
+	}
+}
+
+func TestGetTeamReportsLoadAndCapacity(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectQuery(teamSettingsPattern).
+		WithArgs(teamBackend).
+		WillReturnRows(sqlmock.NewRows([]string{"reviewer_strategy", "min_reviewers", "max_reviewers", "default_max_open_reviews", "pairing_window", "required_role", "required_role_count", "required_approvals"}).
+			AddRow(nil, DefaultMinReviewers, DefaultMaxReviewers, 3, 0, "", 0, 0))
+	mock.ExpectQuery(`SELECT u.user_id, u.username, u.is_active`).
+		WithArgs(teamBackend, StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "is_active", "review_weight", "max_open_reviews", "open_reviews", "role"}).
+			AddRow("u1", "alice", true, 1, 0, 2, RoleMember).
+			AddRow("u2", "bob", true, 1, 1, 1, RoleMember))
+	expectTeamSkills(mock, teamBackend)
+	expectFallbackTeams(mock, teamBackend)
+
+	team, err := store.GetTeam(context.Background(), teamBackend)
+	if err != nil {
+		t.Fatalf("GetTeam error: %v", err)
+	}
+	if team.DefaultMaxOpenReviews != 3 {
+		t.Fatalf("team default = %d", team.DefaultMaxOpenReviews)
+	}
+	if m := team.Members[0]; m.OpenReviews != 2 || m.Capacity != 3 {
+		t.Fatalf("unexpected load of u1: %+v", m)
+	}
+	if m := team.Members[1]; m.OpenReviews != 1 || m.Capacity != 1 {
+		t.Fatalf("unexpected load of u2: %+v", m)
+	}
+}
+
+func TestSetMaxOpenReviews(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`UPDATE users\s+SET max_open_reviews`).
+		WithArgs("u1", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "team_name", "is_active", "max_open_reviews"}).
+			AddRow("u1", "alice", teamBackend, true, 2))
+
+	u, err := store.SetMaxOpenReviews(context.Background(), SetMaxOpenReviewsPayload{UserID: "u1", MaxOpenReviews: 2})
+	if err != nil {
+		t.Fatalf("SetMaxOpenReviews error: %v", err)
+	}
+	if u.MaxOpenReviews != 2 {
+		t.Fatalf("unexpected user: %+v", u)
+	}
+}
+
+func TestSetMaxOpenReviewsValidation(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	_, err := store.SetMaxOpenReviews(context.Background(), SetMaxOpenReviewsPayload{UserID: "u1", MaxOpenReviews: -1})
+	if !errors.Is(err, ErrInvalidCapacity) {
+		t.Fatalf("expected ErrInvalidCapacity, got %v", err)
+	}
+
+	mock.ExpectQuery(`UPDATE users\s+SET max_open_reviews`).
+		WithArgs("u404", 0).
+		WillReturnError(sql.ErrNoRows)
+	_, err = store.SetMaxOpenReviews(context.Background(), SetMaxOpenReviewsPayload{UserID: "u404"})
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestUpdateTeamSettingsRejectsNegativeCapacity(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(teamSettingsPattern + `\s+WHERE name=\$1 FOR UPDATE`).
+		WithArgs("platform").
+		WillReturnRows(teamSettingsRows(nil, 1, 2))
+	mock.ExpectRollback()
+
+	capacity := -1
+	_, err := store.UpdateTeamSettings(context.Background(), TeamSettingsPayload{
+		TeamName:              "platform",
+		DefaultMaxOpenReviews: &capacity,
+	})
+	if !errors.Is(err, ErrInvalidCapacity) {
+		t.Fatalf("expected ErrInvalidCapacity, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package storage
+
+// Reasons a team member was left out of the candidate pool.
+const (
+	ExcludedAuthor          = "author"
+	ExcludedInactive        = "inactive"
+	ExcludedAlreadyAssigned = "already_assigned"
+	ExcludedAtCapacity      = "at_capacity"
+	ExcludedOutOfOffice     = "out_of_office"
+	ExcludedByAuthor        = "excluded_by_author"
+)
+
+// SelectionExplanation describes one reviewer selection round within a team.
+type SelectionExplanation struct {
+	TeamName string `json:"team_name"`
+	Strategy string `json:"strategy"`
+	// Fallback is set when the team was consulted as a fallback of another team.
+	Fallback bool `json:"fallback,omitempty"`
+	// Owners is set for the round assigning owners of changed files; its pool
+	// holds the eligible owners, who may come from any team.
+	Owners bool `json:"owners,omitempty"`
+	// Requested is set for the round assigning the reviewers the author asked for.
+	Requested bool        `json:"requested,omitempty"`
+	Pool      []string    `json:"pool"`
+	Excluded  []Exclusion `json:"excluded"`
+	Selected  []string    `json:"selected"`
+	// RecentPairings holds, for pool members who reviewed the author's recent
+	// PRs, how many of them; set only when the team has a pairing window.
+	RecentPairings map[string]int `json:"recent_pairings,omitempty"`
+	// PolicyReviewers are the selected reviewers picked to satisfy the team role policy.
+	PolicyReviewers []string `json:"policy_reviewers,omitempty"`
+	// CoveredTags maps each required tag covered in this round to the reviewer covering it.
+	CoveredTags map[string]string `json:"covered_tags,omitempty"`
+}
+
+type Exclusion struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+type teamMember struct {
+	Candidate
+	active bool
+	absent bool
+}
+
+// eligibleCandidates splits team members into the candidate pool and the
+// excluded ones. exclude is the PR author, block holds users already on the PR.
+func eligibleCandidates(
+	members []teamMember,
+	exclude string,
+	block map[string]struct{},
+	teamCapacity int,
+) ([]Candidate, []Exclusion) {
+	candidates := make([]Candidate, 0, len(members))
+	excluded := make([]Exclusion, 0)
+	for _, m := range members {
+		if reason := exclusionReason(m, exclude, block, teamCapacity); reason != "" {
+			excluded = append(excluded, Exclusion{UserID: m.UserID, Reason: reason})
+			continue
+		}
+		candidates = append(candidates, m.Candidate)
+	}
+	return candidates, excluded
+}
+
+func exclusionReason(m teamMember, exclude string, block map[string]struct{}, teamCapacity int) string {
+	if m.UserID == exclude {
+		return ExcludedAuthor
+	}
+	if _, ok := block[m.UserID]; ok {
+		return ExcludedAlreadyAssigned
+	}
+	if !m.active {
+		return ExcludedInactive
+	}
+	if m.absent {
+		return ExcludedOutOfOffice
+	}
+	if capacity := effectiveCapacity(m.MaxOpenReviews, teamCapacity); capacity > 0 && m.OpenReviews >= capacity {
+		return ExcludedAtCapacity
+	}
+	return ""
+}
+
+// dropExcluded moves the candidates the author excluded from the PR over to
+// the excluded ones.
+func dropExcluded(
+	candidates []Candidate,
+	excluded []Exclusion,
+	byAuthor map[string]struct{},
+) ([]Candidate, []Exclusion) {
+	if len(byAuthor) == 0 {
+		return candidates, excluded
+	}
+	kept := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if _, ok := byAuthor[c.UserID]; ok {
+			excluded = append(excluded, Exclusion{UserID: c.UserID, Reason: ExcludedByAuthor})
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept, excluded
+}
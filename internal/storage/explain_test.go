@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestEligibleCandidatesReasons(t *testing.T) {
+	members := []teamMember{
+		{Candidate: Candidate{UserID: "author"}, active: true},
+		{Candidate: Candidate{UserID: "assigned"}, active: true},
+		{Candidate: Candidate{UserID: "inactive"}},
+		{Candidate: Candidate{UserID: "away"}, active: true, absent: true},
+		{Candidate: Candidate{UserID: "full", OpenReviews: 2, MaxOpenReviews: 2}, active: true},
+		{Candidate: Candidate{UserID: "ok"}, active: true},
+	}
+	block := map[string]struct{}{"assigned": {}}
+	candidates, excluded := eligibleCandidates(members, "author", block, 0)
+	if ids := candidateIDs(candidates); !reflect.DeepEqual(ids, []string{"ok"}) {
+		t.Fatalf("pool = %v", ids)
+	}
+	want := []Exclusion{
+		{UserID: "author", Reason: ExcludedAuthor},
+		{UserID: "assigned", Reason: ExcludedAlreadyAssigned},
+		{UserID: "inactive", Reason: ExcludedInactive},
+		{UserID: "away", Reason: ExcludedOutOfOffice},
+		{UserID: "full", Reason: ExcludedAtCapacity},
+	}
+	if !reflect.DeepEqual(excluded, want) {
+		t.Fatalf("excluded = %+v, want %+v", excluded, want)
+	}
+}
+
+func TestCreatePRExplain(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 1, 1)
+	mock.ExpectQuery(membersPattern).
+		WithArgs(teamBackend, StatusOpen).
+		WillReturnRows(sqlmock.NewRows(memberColumns).
+			AddRow(authorID, true, 1, 0, false, nil, 0, RoleMember).
+			AddRow("u1", false, 1, 0, false, nil, 0, RoleMember).
+			AddRow("u2", true, 1, 0, false, nil, 0, RoleMember))
+	mock.ExpectExec(`INSERT INTO pull_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assignment_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	pr, err := store.CreatePR(context.Background(), CreatePRPayload{
+		ID: "pr1", Name: "feature", Author: authorID, Explain: true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePR error: %v", err)
+	}
+	if len(pr.Explanation) != 1 {
+		t.Fatalf("expected one selection round, got %+v", pr.Explanation)
+	}
+	round := pr.Explanation[0]
+	if round.TeamName != teamBackend || round.Strategy != StrategyRandom {
+		t.Fatalf("unexpected round: %+v", round)
+	}
+	if !reflect.DeepEqual(round.Pool, []string{"u2"}) || !reflect.DeepEqual(round.Selected, []string{"u2"}) {
+		t.Fatalf("unexpected pool/selection: %+v", round)
+	}
+	wantExcluded := []Exclusion{{UserID: authorID, Reason: ExcludedAuthor}, {UserID: "u1", Reason: ExcludedInactive}}
+	if !reflect.DeepEqual(round.Excluded, wantExcluded) {
+		t.Fatalf("excluded = %+v", round.Excluded)
+	}
+}
+
+func TestCreatePRWithoutExplainOmitsExplanation(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 1, 1)
+	expectCandidateRows(mock, teamBackend, "u1")
+	mock.ExpectExec(`INSERT INTO pull_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assignment_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	pr, err := store.CreatePR(context.Background(), CreatePRPayload{ID: "pr1", Name: "feature", Author: authorID})
+	if err != nil {
+		t.Fatalf("CreatePR error: %v", err)
+	}
+	if pr.Explanation != nil {
+		t.Fatalf("explanation must be omitted, got %+v", pr.Explanation)
+	}
+}
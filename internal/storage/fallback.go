@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+)
+
+// selection is the outcome of filling reviewer slots for a PR.
+type selection struct {
+	reviewers []string
+	// fromFallback lists the reviewers that came from fallback teams.
+	fromFallback []string
+	explanation  []SelectionExplanation
+	// uncoveredTags lists required tags none of the reviewers has.
+	uncoveredTags []string
+	// fromOwners lists the reviewers assigned as owners of changed files.
+	fromOwners []string
+	// uncoveredPaths lists changed files whose owners could not be assigned.
+	uncoveredPaths []string
+	// policyReviewers counts reviewers holding the role the team policy asks for.
+	policyReviewers int
+	// roleShortfall is how many role holders the policy still misses.
+	roleShortfall int
+	// skipped lists requested reviewers left out because they are inactive.
+	skipped []string
+	// missing is how many reviewers the PR lacks to reach the team's min_reviewers.
+	missing int
+}
+
+// then appends the reviewers picked by next, a later round of the same selection.
+func (sel selection) then(next selection) selection {
+	sel.reviewers = append(append(make([]string, 0, len(sel.reviewers)+len(next.reviewers)),
+		sel.reviewers...), next.reviewers...)
+	sel.fromFallback = append(sel.fromFallback, next.fromFallback...)
+	sel.explanation = append(sel.explanation, next.explanation...)
+	sel.fromOwners = append(sel.fromOwners, next.fromOwners...)
+	sel.uncoveredPaths = append(sel.uncoveredPaths, next.uncoveredPaths...)
+	sel.policyReviewers += next.policyReviewers
+	sel.uncoveredTags = next.uncoveredTags
+	sel.roleShortfall = next.roleShortfall
+	return sel
+}
+
+// pickWithFallback fills up to limit slots from the team and, when the team
+// runs short, from its fallback teams in their declared order. Required tags
+// left uncovered by the team are carried over to the fallback teams.
+func (s *Store) pickWithFallback(
+	ctx context.Context,
+	q querier,
+	settings TeamSettings,
+	exclude string,
+	block map[string]struct{},
+	rules selectionRules,
+	limit int,
+) (selection, error) {
+	picked, explanation, err := s.pickFromTeam(ctx, q, settings, exclude, block, rules, limit)
+	if err != nil {
+		return selection{}, err
+	}
+	result := selection{reviewers: picked, explanation: []SelectionExplanation{explanation}}
+	rules.requiredTags = uncoveredTags(rules.requiredTags, explanation.CoveredTags)
+	rules.roleCount -= len(explanation.PolicyReviewers)
+	result.uncoveredTags = rules.requiredTags
+	result.roleShortfall = rules.roleCount
+	if len(picked) < limit {
+		if err := s.fillFromFallback(ctx, q, settings.TeamName, exclude, block, rules, limit, &result); err != nil {
+			return selection{}, err
+		}
+	}
+	if result.roleShortfall > 0 {
+		return selection{}, ErrPolicyUnsatisfiable
+	}
+	if len(result.uncoveredTags) > 0 && rules.requireTags {
+		return selection{}, ErrTagsUncovered
+	}
+	return result, nil
+}
+
+// fillFromFallback appends reviewers from the fallback teams of teamName to
+// result until limit is reached or the fallback teams are exhausted.
+func (s *Store) fillFromFallback(
+	ctx context.Context,
+	q querier,
+	teamName string,
+	exclude string,
+	block map[string]struct{},
+	rules selectionRules,
+	limit int,
+	result *selection,
+) error {
+	fallbackTeams, err := loadFallbackTeams(ctx, q, teamName)
+	if err != nil {
+		return err
+	}
+	if len(fallbackTeams) == 0 {
+		return nil
+	}
+
+	taken := make(map[string]struct{}, len(block)+limit)
+	for id := range block {
+		taken[id] = struct{}{}
+	}
+	for _, id := range result.reviewers {
+		taken[id] = struct{}{}
+	}
+	for _, team := range fallbackTeams {
+		if len(result.reviewers) >= limit {
+			break
+		}
+		fbSettings, err := loadTeamSettings(ctx, q, team)
+		if err != nil {
+			return err
+		}
+		extra, explanation, err := s.pickFromTeam(ctx, q, fbSettings, exclude, taken, rules, limit-len(result.reviewers))
+		if err != nil {
+			return err
+		}
+		for _, id := range extra {
+			taken[id] = struct{}{}
+		}
+		explanation.Fallback = true
+		rules.requiredTags = uncoveredTags(rules.requiredTags, explanation.CoveredTags)
+		rules.roleCount -= len(explanation.PolicyReviewers)
+		result.reviewers = append(result.reviewers, extra...)
+		result.fromFallback = append(result.fromFallback, extra...)
+		result.explanation = append(result.explanation, explanation)
+	}
+	result.uncoveredTags = rules.requiredTags
+	result.roleShortfall = rules.roleCount
+	return nil
+}
+
+func loadFallbackTeams(ctx context.Context, q querier, teamName string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `
+SELECT fallback_team
+FROM team_fallbacks
+WHERE team_name=$1
+ORDER BY position
+`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	teams := make([]string, 0)
+	for rows.Next() {
+		var team string
+		if err := rows.Scan(&team); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+// replaceFallbackTeams overwrites the ordered fallback list of the team.
+func replaceFallbackTeams(ctx context.Context, tx *sql.Tx, teamName string, fallbackTeams []string) error {
+	seen := make(map[string]struct{}, len(fallbackTeams))
+	for _, team := range fallbackTeams {
+		if team == "" || team == teamName {
+			return ErrInvalidFallback
+		}
+		if _, dup := seen[team]; dup {
+			return ErrInvalidFallback
+		}
+		seen[team] = struct{}{}
+		var exists bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS(SELECT 1 FROM teams WHERE name=$1)`, team).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrInvalidFallback
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM team_fallbacks WHERE team_name=$1`, teamName); err != nil {
+		return err
+	}
+	for i, team := range fallbackTeams {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO team_fallbacks(team_name, fallback_team, position)
+VALUES ($1,$2,$3)
+`, teamName, team, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
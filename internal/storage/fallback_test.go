@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCreatePRFillsFromFallbackTeams(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 1, 2)
+	expectCandidateRows(mock, teamBackend, "u1")
+	expectFallbackTeams(mock, teamBackend, "platform", "infra")
+	expectTeamSettings(mock, "platform")
+	expectCandidateRows(mock, "platform", "p1", "p2")
+	mock.ExpectExec(`INSERT INTO pull_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	for i := 0; i < 2; i++ {
+		mock.ExpectExec(`INSERT INTO assigned_reviewers`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO assignment_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	pr, err := store.CreatePR(context.Background(), CreatePRPayload{ID: "pr1", Name: "feature", Author: authorID})
+	if err != nil {
+		t.Fatalf("CreatePR error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 2 || pr.AssignedReviewers[0] != "u1" {
+		t.Fatalf("unexpected reviewers: %v", pr.AssignedReviewers)
+	}
+	if len(pr.FallbackReviewers) != 1 || pr.FallbackReviewers[0] != pr.AssignedReviewers[1] {
+		t.Fatalf("unexpected fallback reviewers: %v", pr.FallbackReviewers)
+	}
+	// The second fallback team is not consulted once all slots are filled.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestPickWithFallbackSkipsAlreadyChosen(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	expectCandidateRows(mock, teamBackend, "u1")
+	expectFallbackTeams(mock, teamBackend, "platform")
+	expectTeamSettings(mock, "platform")
+	// A member reported by both pools must not be picked twice.
+	expectCandidateRows(mock, "platform", "u1", "p1")
+
+	settings := TeamSettings{TeamName: teamBackend, MinReviewers: 1, MaxReviewers: 2}
+	picked, err := store.pickWithFallback(context.Background(), store.db, settings, "", nil, selectionRules{}, 2)
+	if err != nil {
+		t.Fatalf("pickWithFallback error: %v", err)
+	}
+	if !reflect.DeepEqual(picked.reviewers, []string{"u1", "p1"}) || !reflect.DeepEqual(picked.fromFallback, []string{"p1"}) {
+		t.Fatalf("picked=%v fallback=%v", picked.reviewers, picked.fromFallback)
+	}
+	if len(picked.explanation) != 2 || !picked.explanation[1].Fallback {
+		t.Fatalf("unexpected explanation: %+v", picked.explanation)
+	}
+}
+
+func TestReassignUsesFallbackTeam(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT author_id, status FROM pull_requests`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "status"}).AddRow("author", StatusOpen))
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("old"))
+	mock.ExpectQuery(`SELECT team_name FROM users`).WithArgs("old").
+		WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow("backend"))
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM assigned_reviewers`).WithArgs("pr1", "old").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	expectRolePolicy(mock, "pr1", "", 0)
+	expectCandidates(mock, "backend")
+	expectFallbackTeams(mock, "backend", "platform")
+	expectCandidates(mock, "platform", "p1")
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "old").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "old", EventUnassigned, ReasonReassigned)
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "p1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "p1", EventAssigned, ReasonReassigned)
+	mock.ExpectQuery(`SELECT pr_id, pr_name, author_id, status, created_at, merged_at FROM pull_requests`).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "pr_name", "author_id", "status", "created_at", "merged_at"}).
+			AddRow("pr1", "feature", "author", StatusOpen, time.Now(), nil))
+	mock.ExpectQuery(reviewsPattern).WithArgs("pr1").WillReturnRows(reviewRows("p1"))
+	mock.ExpectCommit()
+
+	pr, replaced, err := store.Reassign(context.Background(), ReassignPayload{PRID: "pr1", Old: "old"})
+	if err != nil {
+		t.Fatalf("Reassign error: %v", err)
+	}
+	if replaced != "p1" || !reflect.DeepEqual(pr.FallbackReviewers, []string{"p1"}) {
+		t.Fatalf("unexpected result: %+v, replaced=%s", pr, replaced)
+	}
+}
+
+func TestUpdateTeamSettingsReplacesFallbacks(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(teamSettingsPattern + `\s+WHERE name=\$1 FOR UPDATE`).
+		WithArgs(teamBackend).
+		WillReturnRows(teamSettingsRows(nil, 1, 2))
+	mock.ExpectExec(`UPDATE teams`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).
+		WithArgs("platform").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(`DELETE FROM team_fallbacks`).WithArgs(teamBackend).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO team_fallbacks`).WithArgs(teamBackend, "platform", 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	settings, err := store.UpdateTeamSettings(context.Background(), TeamSettingsPayload{
+		TeamName:      teamBackend,
+		FallbackTeams: []string{"platform"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateTeamSettings error: %v", err)
+	}
+	if !reflect.DeepEqual(settings.FallbackTeams, []string{"platform"}) {
+		t.Fatalf("unexpected fallbacks: %v", settings.FallbackTeams)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestUpdateTeamSettingsRejectsInvalidFallbacks(t *testing.T) {
+	cases := map[string][]string{
+		"self":      {teamBackend},
+		"duplicate": {"platform", "platform"},
+		"unknown":   {"ghost"},
+	}
+	for name, fallbacks := range cases {
+		t.Run(name, func(t *testing.T) {
+			store, mock, cleanup := newMockStore(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(teamSettingsPattern + `\s+WHERE name=\$1 FOR UPDATE`).
+				WithArgs(teamBackend).
+				WillReturnRows(teamSettingsRows(nil, 1, 2))
+			mock.ExpectExec(`UPDATE teams`).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).
+				WithArgs("platform").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).
+				WithArgs("ghost").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			mock.ExpectRollback()
+
+			_, err := store.UpdateTeamSettings(context.Background(), TeamSettingsPayload{
+				TeamName:      teamBackend,
+				FallbackTeams: fallbacks,
+			})
+			if !errors.Is(err, ErrInvalidFallback) {
+				t.Fatalf("expected ErrInvalidFallback, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Assignment event actions.
+const (
+	EventAssigned   = "ASSIGNED"
+	EventUnassigned = "UNASSIGNED"
+)
+
+// Reasons recorded together with assignment events.
+const (
+	ReasonPRCreated       = "pr_created"
+	ReasonReassigned      = "reassigned"
+	ReasonTeamDeactivated = "team_deactivated"
+	ReasonUserDeactivated = "user_deactivated"
+	ReasonReviewerAdded   = "reviewer_added"
+	ReasonReviewerRemoved = "reviewer_removed"
+	ReasonPRReady         = "pr_ready"
+	ReasonPRClosed        = "pr_closed"
+	ReasonPRReopened      = "pr_reopened"
+	ReasonUserMoved       = "user_moved"
+	ReasonUserDeleted     = "user_deleted"
+	ReasonUserRemoved     = "user_removed"
+	ReasonAuthorDeleted   = "author_deleted"
+)
+
+// ActorSystem marks changes that were not attributed to a particular user.
+const ActorSystem = "system"
+
+type AssignmentEvent struct {
+	PRID      string    `json:"pull_request_id"`
+	UserID    string    `json:"user_id"`
+	Action    string    `json:"action"`
+	Reason    string    `json:"reason"`
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PRHistory returns the reviewer assignment timeline of a PR, oldest first.
+func (s *Store) PRHistory(ctx context.Context, prID string) ([]AssignmentEvent, error) {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pr_id=$1)`, prID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrPRNotFound
+	}
+	rows, err := s.db.QueryContext(ctx, `
+SELECT pr_id, user_id, action, reason, actor, created_at
+FROM assignment_events
+WHERE pr_id=$1
+ORDER BY id
+`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	events := make([]AssignmentEvent, 0)
+	for rows.Next() {
+		var e AssignmentEvent
+		if err := rows.Scan(&e.PRID, &e.UserID, &e.Action, &e.Reason, &e.Actor, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// assignReviewer adds a reviewer to the PR and records the event in the same transaction.
+func (s *Store) assignReviewer(ctx context.Context, tx *sql.Tx, prID, userID, reason, actor string) error {
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO assigned_reviewers(pr_id, user_id) VALUES ($1,$2)`,
+		prID,
+		userID,
+	); err != nil {
+		return err
+	}
+	return recordAssignmentEvent(ctx, tx, prID, userID, EventAssigned, reason, actor)
+}
+
+// unassignReviewer removes a reviewer from the PR and records the event in the same transaction.
+func (s *Store) unassignReviewer(ctx context.Context, tx *sql.Tx, prID, userID, reason, actor string) error {
+	if _, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM assigned_reviewers WHERE pr_id=$1 AND user_id=$2`,
+		prID,
+		userID,
+	); err != nil {
+		return err
+	}
+	return recordAssignmentEvent(ctx, tx, prID, userID, EventUnassigned, reason, actor)
+}
+
+func recordAssignmentEvent(ctx context.Context, tx *sql.Tx, prID, userID, action, reason, actor string) error {
+	_, err := tx.ExecContext(ctx, `
+INSERT INTO assignment_events(pr_id, user_id, action, reason, actor)
+VALUES ($1,$2,$3,$4,$5)
+`, prID, userID, action, reason, actor)
+	return err
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestPRHistoryReturnsTimeline(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM pull_requests WHERE pr_id=`).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`FROM assignment_events`).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "user_id", "action", "reason", "actor", "created_at"}).
+			AddRow("pr1", "u1", EventAssigned, ReasonPRCreated, authorID, now).
+			AddRow("pr1", "u1", EventUnassigned, ReasonReassigned, ActorSystem, now).
+			AddRow("pr1", "u2", EventAssigned, ReasonReassigned, ActorSystem, now))
+
+	events, err := store.PRHistory(context.Background(), "pr1")
+	if err != nil {
+		t.Fatalf("PRHistory error: %v", err)
+	}
+	if len(events) != 3 || events[1].Action != EventUnassigned || events[2].UserID != "u2" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestPRHistoryPRNotFound(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM pull_requests WHERE pr_id=`).
+		WithArgs("pr404").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	if _, err := store.PRHistory(context.Background(), "pr404"); !errors.Is(err, ErrPRNotFound) {
+		t.Fatalf("expected ErrPRNotFound, got %v", err)
+	}
+}
+
+func TestReplaceReviewerRecordsEvents(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "old").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assignment_events`).
+		WithArgs("pr1", "old", EventUnassigned, ReasonReassigned, ActorSystem).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "new").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assignment_events`).
+		WithArgs("pr1", "new", EventAssigned, ReasonReassigned, ActorSystem).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	tx, _ := store.db.Begin()
+	if err := store.replaceReviewer(context.Background(), tx, "pr1", "old", "new"); err != nil {
+		t.Fatalf("replaceReviewer error: %v", err)
+	}
+	_ = tx.Rollback()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// prTransitions is the PR state machine: the statuses a PR in a given status
+// may move to. Merging is idempotent, so MERGED may move to itself.
+var prTransitions = map[string][]string{
+	StatusDraft:  {StatusOpen, StatusClosed},
+	StatusOpen:   {StatusMerged, StatusClosed},
+	StatusClosed: {StatusOpen},
+	StatusMerged: {StatusMerged},
+}
+
+func checkTransition(from, to string) error {
+	if !slices.Contains(prTransitions[from], to) {
+		return fmt.Errorf("%w: cannot move a %s PR to %s", ErrInvalidStatus, from, to)
+	}
+	return nil
+}
+
+// lockPR locks the PR row for the rest of the transaction and returns its
+// author and status. The author is empty once the author's user is deleted.
+func lockPR(ctx context.Context, tx *sql.Tx, prID string) (string, string, error) {
+	var authorID sql.NullString
+	var status string
+	if err := tx.QueryRowContext(ctx, `SELECT author_id, status FROM pull_requests WHERE pr_id=$1 FOR UPDATE`, prID).
+		Scan(&authorID, &status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", ErrPRNotFound
+		}
+		return "", "", err
+	}
+	return authorID.String, status, nil
+}
+
+// lockPRTransition locks the PR and checks that it may move to status.
+func lockPRTransition(ctx context.Context, tx *sql.Tx, prID, status string) error {
+	_, current, err := lockPR(ctx, tx, prID)
+	if err != nil {
+		return err
+	}
+	return checkTransition(current, status)
+}
+
+func setPRStatus(ctx context.Context, tx *sql.Tx, prID, status string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE pull_requests SET status = $2 WHERE pr_id = $1`, prID, status)
+	return err
+}
+
+// ClosePR abandons a draft or open PR without merging it. Its reviewers are
+// unassigned, so the PR no longer counts towards their load.
+func (s *Store) ClosePR(ctx context.Context, id string) (*PullRequest, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	if err := lockPRTransition(ctx, tx, id, StatusClosed); err != nil {
+		return nil, err
+	}
+	if err := s.closePRTx(ctx, tx, id, ReasonPRClosed); err != nil {
+		return nil, err
+	}
+	pr, err := s.fetchPR(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// closePRTx unassigns the reviewers of a locked PR and marks it CLOSED.
+func (s *Store) closePRTx(ctx context.Context, tx *sql.Tx, id, reason string) error {
+	reviewers, err := s.listReviewersTx(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	for _, reviewer := range reviewers {
+		if err := s.unassignReviewer(ctx, tx, id, reviewer, reason, ActorSystem); err != nil {
+			return err
+		}
+	}
+	return setPRStatus(ctx, tx, id, StatusClosed)
+}
+
+// ReadyPR moves a draft to OPEN and assigns its reviewers the way CreatePR
+// does; the selection options are taken from payload, the author from the PR.
+func (s *Store) ReadyPR(ctx context.Context, payload CreatePRPayload) (*PullRequest, error) {
+	return s.openPR(ctx, payload, StatusDraft, ReasonPRReady)
+}
+
+// ReopenPR moves a closed PR back to OPEN with a fresh reviewer selection.
+// Verdicts were dropped with the reviewers on close, so approvals start over.
+func (s *Store) ReopenPR(ctx context.Context, payload CreatePRPayload) (*PullRequest, error) {
+	return s.openPR(ctx, payload, StatusClosed, ReasonPRReopened)
+}
+
+func (s *Store) openPR(ctx context.Context, payload CreatePRPayload, from, reason string) (*PullRequest, error) {
+	for attempts := 0; attempts < 3; attempts++ {
+		pr, err := s.openPROnce(ctx, payload, from, reason)
+		if err == nil {
+			return pr, nil
+		}
+		if isSerializationError(err) && attempts < 2 {
+			time.Sleep(time.Duration(attempts+1) * 10 * time.Millisecond)
+			continue
+		}
+		return nil, err
+	}
+	return nil, fmt.Errorf("unreachable")
+}
+
+func (s *Store) openPROnce(ctx context.Context, payload CreatePRPayload, from, reason string) (*PullRequest, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	authorID, status, err := lockPR(ctx, tx, payload.ID)
+	if err != nil {
+		return nil, err
+	}
+	if status != from {
+		return nil, fmt.Errorf("%w: expected a %s PR, got %s", ErrInvalidStatus, from, status)
+	}
+	if err := checkTransition(status, StatusOpen); err != nil {
+		return nil, err
+	}
+	// Reviewers are picked from the author's team, which is gone with the author.
+	if authorID == "" {
+		return nil, fmt.Errorf("%w: the author of %s was deleted", ErrInvalidStatus, payload.ID)
+	}
+	payload.Author = authorID
+	picked, err := s.selectForNewPR(ctx, tx, payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := setPRStatus(ctx, tx, payload.ID, StatusOpen); err != nil {
+		return nil, err
+	}
+	for _, reviewer := range picked.reviewers {
+		if err := s.assignReviewer(ctx, tx, payload.ID, reviewer, reason, ActorSystem); err != nil {
+			return nil, err
+		}
+	}
+	pr, err := s.fetchPR(ctx, tx, payload.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	pr.FallbackReviewers = picked.fromFallback
+	pr.UncoveredTags = picked.uncoveredTags
+	pr.OwnerReviewers = picked.fromOwners
+	pr.UncoveredPaths = picked.uncoveredPaths
+	pr.SkippedReviewers = picked.skipped
+	pr.MissingReviewers = picked.missing
+	if payload.Explain {
+		pr.Explanation = picked.explanation
+	}
+	return pr, nil
+}
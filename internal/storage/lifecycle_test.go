@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectLockPR(mock sqlmock.Sqlmock, status string) {
+	mock.ExpectQuery(`SELECT author_id, status FROM pull_requests WHERE pr_id=\$1 FOR UPDATE`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "status"}).AddRow(authorID, status))
+}
+
+func expectFetchPRStatus(mock sqlmock.Sqlmock, status string, reviewers ...string) {
+	mock.ExpectQuery(`SELECT pr_id, pr_name, author_id, status, created_at, merged_at FROM pull_requests`).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "pr_name", "author_id", "status", "created_at", "merged_at"}).
+			AddRow("pr1", "feature", authorID, status, time.Now(), nil))
+	mock.ExpectQuery(reviewsPattern).WithArgs("pr1").WillReturnRows(reviewRows(reviewers...))
+}
+
+func TestCheckTransition(t *testing.T) {
+	cases := []struct {
+		from, to string
+		ok       bool
+	}{
+		{StatusDraft, StatusOpen, true},
+		{StatusDraft, StatusClosed, true},
+		{StatusDraft, StatusMerged, false},
+		{StatusOpen, StatusMerged, true},
+		{StatusOpen, StatusClosed, true},
+		{StatusOpen, StatusOpen, false},
+		{StatusClosed, StatusOpen, true},
+		{StatusClosed, StatusMerged, false},
+		{StatusMerged, StatusMerged, true},
+		{StatusMerged, StatusClosed, false},
+		{StatusMerged, StatusOpen, false},
+	}
+	for _, tc := range cases {
+		err := checkTransition(tc.from, tc.to)
+		if tc.ok != (err == nil) {
+			t.Fatalf("checkTransition(%s, %s) = %v", tc.from, tc.to, err)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidStatus) {
+			t.Fatalf("expected ErrInvalidStatus, got %v", err)
+		}
+	}
+}
+
+func TestCreateDraftPRAssignsNoReviewers(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM pull_requests WHERE pr_id=`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`SELECT user_id, team_name FROM users WHERE user_id=`).WithArgs(authorID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_name"}).AddRow(authorID, teamBackend))
+	mock.ExpectExec(`INSERT INTO pull_requests`).
+		WithArgs("pr1", "feature", authorID, StatusDraft, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	pr, err := store.CreatePR(context.Background(), CreatePRPayload{ID: "pr1", Name: "feature", Author: authorID, Draft: true})
+	if err != nil {
+		t.Fatalf("CreatePR error: %v", err)
+	}
+	if pr.Status != StatusDraft || len(pr.AssignedReviewers) != 0 {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestReadyPRAssignsReviewers(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockPR(mock, StatusDraft)
+	mock.ExpectQuery(`SELECT user_id, team_name FROM users WHERE user_id=`).WithArgs(authorID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_name"}).AddRow(authorID, teamBackend))
+	expectCandidates(mock, teamBackend, "u1", "u2")
+	mock.ExpectExec(`UPDATE pull_requests SET status`).WithArgs("pr1", StatusOpen).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, id := range []string{"u1", "u2"} {
+		mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", id).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectEvent(mock, "pr1", id, EventAssigned, ReasonPRReady)
+	}
+	expectFetchPRStatus(mock, StatusOpen, "u1", "u2")
+	mock.ExpectCommit()
+
+	pr, err := store.ReadyPR(context.Background(), CreatePRPayload{ID: "pr1"})
+	if err != nil {
+		t.Fatalf("ReadyPR error: %v", err)
+	}
+	if pr.Status != StatusOpen || len(pr.AssignedReviewers) != 2 {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestOpenPRRejectsWrongStatus(t *testing.T) {
+	cases := []struct {
+		name   string
+		status string
+		open   func(*Store, context.Context, CreatePRPayload) (*PullRequest, error)
+	}{
+		{"ready open", StatusOpen, (*Store).ReadyPR},
+		{"ready closed", StatusClosed, (*Store).ReadyPR},
+		{"reopen draft", StatusDraft, (*Store).ReopenPR},
+		{"reopen merged", StatusMerged, (*Store).ReopenPR},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store, mock, cleanup := newMockStore(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			expectLockPR(mock, tc.status)
+			mock.ExpectRollback()
+
+			_, err := tc.open(store, context.Background(), CreatePRPayload{ID: "pr1"})
+			if !errors.Is(err, ErrInvalidStatus) {
+				t.Fatalf("expected ErrInvalidStatus, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReopenPRRejectsDeletedAuthor(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT author_id, status FROM pull_requests WHERE pr_id=\$1 FOR UPDATE`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "status"}).AddRow(nil, StatusClosed))
+	mock.ExpectRollback()
+
+	_, err := store.ReopenPR(context.Background(), CreatePRPayload{ID: "pr1"})
+	if !errors.Is(err, ErrInvalidStatus) || !strings.Contains(err.Error(), "deleted") {
+		t.Fatalf("expected ErrInvalidStatus, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestClosePRUnassignsReviewers(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockPR(mock, StatusOpen)
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u1").AddRow("u2"))
+	for _, id := range []string{"u1", "u2"} {
+		mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", id).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectEvent(mock, "pr1", id, EventUnassigned, ReasonPRClosed)
+	}
+	mock.ExpectExec(`UPDATE pull_requests SET status`).WithArgs("pr1", StatusClosed).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectFetchPRStatus(mock, StatusClosed)
+	mock.ExpectCommit()
+
+	pr, err := store.ClosePR(context.Background(), "pr1")
+	if err != nil {
+		t.Fatalf("ClosePR error: %v", err)
+	}
+	if pr.Status != StatusClosed || len(pr.AssignedReviewers) != 0 {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestReopenPRResetsApprovals(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	// Closing deletes the reviewer rows and the verdicts stored on them.
+	mock.ExpectBegin()
+	expectLockPR(mock, StatusOpen)
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u1"))
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "u1").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "u1", EventUnassigned, ReasonPRClosed)
+	mock.ExpectExec(`UPDATE pull_requests SET status`).WithArgs("pr1", StatusClosed).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectFetchPRStatus(mock, StatusClosed)
+	mock.ExpectCommit()
+	if _, err := store.ClosePR(context.Background(), "pr1"); err != nil {
+		t.Fatalf("ClosePR error: %v", err)
+	}
+
+	// Reopening assigns the same reviewer again, without a verdict.
+	mock.ExpectBegin()
+	expectLockPR(mock, StatusClosed)
+	mock.ExpectQuery(`SELECT user_id, team_name FROM users WHERE user_id=`).WithArgs(authorID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_name"}).AddRow(authorID, teamBackend))
+	mock.ExpectQuery(teamSettingsPattern).WithArgs(teamBackend).WillReturnRows(teamSettingsRows(nil, 1, 1))
+	expectCandidateRows(mock, teamBackend, "u1")
+	mock.ExpectExec(`UPDATE pull_requests SET status`).WithArgs("pr1", StatusOpen).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assigned_reviewers\(pr_id, user_id\) VALUES`).WithArgs("pr1", "u1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "u1", EventAssigned, ReasonPRReopened)
+	expectFetchPRStatus(mock, StatusOpen, "u1")
+	mock.ExpectCommit()
+	pr, err := store.ReopenPR(context.Background(), CreatePRPayload{ID: "pr1"})
+	if err != nil {
+		t.Fatalf("ReopenPR error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 1 || len(pr.Reviews) != 0 {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestLifecycleRejectsInvalidTransitions(t *testing.T) {
+	t.Run("merge draft", func(t *testing.T) {
+		store, mock, cleanup := newMockStore(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		expectLockPR(mock, StatusDraft)
+		mock.ExpectRollback()
+
+		if _, err := store.MergePR(context.Background(), "pr1"); !errors.Is(err, ErrInvalidStatus) {
+			t.Fatalf("expected ErrInvalidStatus, got %v", err)
+		}
+	})
+	t.Run("close merged", func(t *testing.T) {
+		store, mock, cleanup := newMockStore(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		expectLockPR(mock, StatusMerged)
+		mock.ExpectRollback()
+
+		if _, err := store.ClosePR(context.Background(), "pr1"); !errors.Is(err, ErrInvalidStatus) {
+			t.Fatalf("expected ErrInvalidStatus, got %v", err)
+		}
+	})
+	t.Run("reassign closed", func(t *testing.T) {
+		store, mock, cleanup := newMockStore(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		expectLockPR(mock, StatusClosed)
+		mock.ExpectRollback()
+
+		_, _, err := store.Reassign(context.Background(), ReassignPayload{PRID: "pr1", Old: "u1"})
+		if !errors.Is(err, ErrInvalidStatus) {
+			t.Fatalf("expected ErrInvalidStatus, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,8 @@
+-- Стратегия выбора ревьюеров на уровне команды (NULL — стратегия по умолчанию из конфига)
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS reviewer_strategy TEXT;
+
+-- Вес пользователя для стратегии weighted
+ALTER TABLE users ADD COLUMN IF NOT EXISTS review_weight INT NOT NULL DEFAULT 1;
+
+-- Время назначения нужно стратегии round_robin
+ALTER TABLE assigned_reviewers ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMPTZ NOT NULL DEFAULT now();
@@ -0,0 +1,3 @@
+-- Границы количества ревьюеров на уровне команды (по умолчанию — прежние 1..2)
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS min_reviewers INT NOT NULL DEFAULT 1;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS max_reviewers INT NOT NULL DEFAULT 2;
@@ -0,0 +1,12 @@
+-- Окна отсутствия пользователей: пока now() попадает в [starts_at, ends_at),
+-- пользователь не рассматривается как кандидат в ревьюеры.
+CREATE TABLE IF NOT EXISTS user_absences (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_absences_user ON user_absences(user_id, starts_at);
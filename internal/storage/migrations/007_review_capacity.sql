@@ -0,0 +1,4 @@
+-- Лимит одновременных открытых ревью. NULL у пользователя — берётся значение
+-- команды, NULL у команды — лимита нет.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS max_open_reviews INT;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS default_max_open_reviews INT;
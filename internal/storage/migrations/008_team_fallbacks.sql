@@ -0,0 +1,8 @@
+-- Резервные команды: если в команде не хватает кандидатов, недостающие
+-- ревьюеры добираются из перечисленных команд по возрастанию position.
+CREATE TABLE IF NOT EXISTS team_fallbacks (
+    team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+    fallback_team TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+    position INT NOT NULL,
+    PRIMARY KEY (team_name, fallback_team)
+);
@@ -0,0 +1,4 @@
+-- Память ротации: сколько последних PR автора учитывать, чтобы не ставить
+-- одних и тех же ревьюеров. NULL — ротация выключена.
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS pairing_window INT;
+CREATE INDEX IF NOT EXISTS idx_pull_requests_author_created ON pull_requests(author_id, created_at DESC);
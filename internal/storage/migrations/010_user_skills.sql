@@ -0,0 +1,9 @@
+-- Навыки пользователей (теги вроде go, sql, frontend) для подбора ревьюеров
+-- под required_tags PR. Теги хранятся в нижнем регистре.
+CREATE TABLE IF NOT EXISTS user_skills (
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    skill TEXT NOT NULL,
+    PRIMARY KEY (user_id, skill)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_skills_skill ON user_skills(skill);
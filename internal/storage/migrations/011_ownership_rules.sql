@@ -0,0 +1,19 @@
+-- Правила владения путями в стиле CODEOWNERS, загружаются целиком на команду
+-- автора. Порядок важен: для файла действует последнее совпавшее правило.
+CREATE TABLE IF NOT EXISTS ownership_rules (
+    team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+    position INT NOT NULL,
+    pattern TEXT NOT NULL,
+    PRIMARY KEY (team_name, position)
+);
+
+-- Владелец правила — либо пользователь, либо команда.
+CREATE TABLE IF NOT EXISTS ownership_owners (
+    team_name TEXT NOT NULL,
+    position INT NOT NULL,
+    owner_position INT NOT NULL,
+    user_id TEXT REFERENCES users(user_id) ON DELETE CASCADE,
+    owner_team TEXT REFERENCES teams(name) ON DELETE CASCADE,
+    PRIMARY KEY (team_name, position, owner_position),
+    FOREIGN KEY (team_name, position) REFERENCES ownership_rules(team_name, position) ON DELETE CASCADE
+);
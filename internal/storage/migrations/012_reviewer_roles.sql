@@ -0,0 +1,5 @@
+-- Роли участников команды и политика команды вида "хотя бы N ревьюеров
+-- с ролью не ниже senior". NULL/0 в политике — требований нет.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'member';
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS required_role TEXT;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS required_role_count INT;
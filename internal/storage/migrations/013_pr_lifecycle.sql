@@ -0,0 +1,10 @@
+-- Жизненный цикл PR: DRAFT -> OPEN -> MERGED, OPEN/DRAFT -> CLOSED -> OPEN.
+-- Переходы проверяет приложение, а CHECK не даёт записать неизвестный статус.
+-- Миграции выполняются при каждом старте, поэтому ограничение добавляется один раз.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'pull_requests_status_valid') THEN
+        ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_status_valid
+            CHECK (status IN ('DRAFT', 'OPEN', 'MERGED', 'CLOSED'));
+    END IF;
+END $$;
@@ -0,0 +1,6 @@
+-- Вердикты ревьюеров (APPROVED, CHANGES_REQUESTED, COMMENTED) хранятся прямо
+-- в назначении: при переназначении или снятии ревьюера вердикт уходит вместе с ним.
+-- required_approvals — сколько одобрений нужно PR команды для merge, NULL/0 — не требуется.
+ALTER TABLE assigned_reviewers ADD COLUMN IF NOT EXISTS verdict TEXT;
+ALTER TABLE assigned_reviewers ADD COLUMN IF NOT EXISTS verdict_at TIMESTAMPTZ;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS required_approvals INT;
@@ -0,0 +1,2 @@
+-- Индекс под keyset-пагинацию /pullRequest/list: сначала новые PR.
+CREATE INDEX IF NOT EXISTS idx_pull_requests_created ON pull_requests(created_at DESC, pr_id DESC);
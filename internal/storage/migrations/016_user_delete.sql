@@ -0,0 +1,19 @@
+-- Удаление пользователя: PR, которые он создал, остаются в истории с пустым
+-- author_id. Ограничение пересоздаётся только если ещё не ON DELETE SET NULL,
+-- чтобы не перепроверять его на каждом старте.
+ALTER TABLE pull_requests ALTER COLUMN author_id DROP NOT NULL;
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'pull_requests_author_id_fkey' AND confdeltype = 'n'
+    ) THEN
+        ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_author_id_fkey;
+        ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_author_id_fkey
+            FOREIGN KEY (author_id) REFERENCES users(user_id) ON DELETE SET NULL;
+    END IF;
+END $$;
+
+-- Назначения и вердикты — история ревью, поэтому assigned_reviewers.user_id,
+-- как и в assignment_events, остаётся без внешнего ключа на users.
+ALTER TABLE assigned_reviewers DROP CONSTRAINT IF EXISTS assigned_reviewers_user_id_fkey;
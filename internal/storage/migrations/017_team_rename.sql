@@ -0,0 +1,18 @@
+-- Переименование команды: имя команды — внешний ключ в users, team_fallbacks и
+-- правилах владения, поэтому все такие ключи получают ON UPDATE CASCADE.
+-- Пересоздаются только ключи без этого правила, повторный запуск ничего не меняет.
+DO $$
+DECLARE
+    fk RECORD;
+BEGIN
+    FOR fk IN
+        SELECT conrelid::regclass AS tbl, conname, pg_get_constraintdef(oid) AS def
+        FROM pg_constraint
+        WHERE contype = 'f'
+          AND confrelid IN ('teams'::regclass, 'ownership_rules'::regclass)
+          AND confupdtype <> 'c'
+    LOOP
+        EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', fk.tbl, fk.conname);
+        EXECUTE format('ALTER TABLE %s ADD CONSTRAINT %I %s ON UPDATE CASCADE', fk.tbl, fk.conname, fk.def);
+    END LOOP;
+END $$;
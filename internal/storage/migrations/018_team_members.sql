@@ -0,0 +1,4 @@
+-- Участник, убранный из команды через /team/removeMembers, остаётся без команды:
+-- учётная запись, его PR и история ревью сохраняются, а /team/addMembers или
+-- /users/moveTeam снова включают его в команду.
+ALTER TABLE users ALTER COLUMN team_name DROP NOT NULL;
@@ -0,0 +1,9 @@
+-- Команде нужен хотя бы один ревьюер: нулевой min_reviewers поднимается до 1,
+-- а CHECK не даёт записать его снова. Ограничение добавляется один раз.
+UPDATE teams SET min_reviewers = 1 WHERE min_reviewers < 1;
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'teams_min_reviewers_positive') THEN
+        ALTER TABLE teams ADD CONSTRAINT teams_min_reviewers_positive CHECK (min_reviewers >= 1);
+    END IF;
+END $$;
@@ -0,0 +1,17 @@
+-- Команда PR запоминается при создании: фильтр team_name в /pullRequest/list
+-- не должен зависеть от того, куда автор перешёл потом. Существующие PR один раз
+-- получают текущую команду автора; при удалении команды поле обнуляется.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'pull_requests' AND column_name = 'team_name'
+    ) THEN
+        ALTER TABLE pull_requests ADD COLUMN team_name TEXT
+            REFERENCES teams(name) ON UPDATE CASCADE ON DELETE SET NULL;
+        UPDATE pull_requests pr SET team_name = u.team_name
+        FROM users u
+        WHERE u.user_id = pr.author_id;
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS idx_pull_requests_team ON pull_requests(team_name, created_at DESC, pr_id DESC);
@@ -0,0 +1,475 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// OwnershipRule is one CODEOWNERS line: files matching Pattern are owned by Owners.
+// A rule without owners removes ownership set by earlier rules.
+type OwnershipRule struct {
+	Pattern string  `json:"pattern"`
+	Owners  []Owner `json:"owners"`
+}
+
+// Owner is either a user or a whole team.
+type Owner struct {
+	UserID   string `json:"user_id,omitempty"`
+	TeamName string `json:"team_name,omitempty"`
+}
+
+// OwnershipPayload carries the CODEOWNERS file of a team as plain text.
+type OwnershipPayload struct {
+	TeamName   string `json:"team_name"`
+	CodeOwners string `json:"codeowners"`
+}
+
+type Ownership struct {
+	TeamName string          `json:"team_name"`
+	Rules    []OwnershipRule `json:"rules"`
+}
+
+// ParseCodeOwners reads CODEOWNERS syntax: one "pattern owner..." per line,
+// '#' starts a comment. "@user" names a user, "@org/team" a team (the org is
+// ignored), bare tokens are user ids.
+func ParseCodeOwners(text string) ([]OwnershipRule, error) {
+	rules := make([]OwnershipRule, 0)
+	for n, line := range strings.Split(text, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if strings.HasPrefix(fields[0], "!") {
+			return nil, fmt.Errorf("%w: line %d: negated patterns are not supported", ErrInvalidOwnership, n+1)
+		}
+		if _, err := compileOwnershipPattern(fields[0]); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidOwnership, n+1, err)
+		}
+		rule := OwnershipRule{Pattern: fields[0], Owners: make([]Owner, 0, len(fields)-1)}
+		for _, token := range fields[1:] {
+			name := strings.TrimPrefix(token, "@")
+			if i := strings.LastIndex(name, "/"); i >= 0 {
+				name = name[i+1:]
+				if name == "" {
+					return nil, fmt.Errorf("%w: line %d: empty team in %q", ErrInvalidOwnership, n+1, token)
+				}
+				rule.Owners = append(rule.Owners, Owner{TeamName: name})
+				continue
+			}
+			if name == "" {
+				return nil, fmt.Errorf("%w: line %d: empty owner", ErrInvalidOwnership, n+1)
+			}
+			rule.Owners = append(rule.Owners, Owner{UserID: name})
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SetOwnership replaces the ownership rules of a team with the parsed file.
+func (s *Store) SetOwnership(ctx context.Context, payload OwnershipPayload) (*Ownership, error) {
+	rules, err := ParseCodeOwners(payload.CodeOwners)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	if err := requireTeam(ctx, tx, payload.TeamName); err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if err := validateOwners(ctx, tx, rule.Owners); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ownership_rules WHERE team_name=$1`, payload.TeamName); err != nil {
+		return nil, err
+	}
+	for pos, rule := range rules {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO ownership_rules(team_name, position, pattern) VALUES ($1, $2, $3)`,
+			payload.TeamName, pos, rule.Pattern); err != nil {
+			return nil, err
+		}
+		for ownerPos, owner := range rule.Owners {
+			if _, err := tx.ExecContext(ctx, `
+INSERT INTO ownership_owners(team_name, position, owner_position, user_id, owner_team)
+VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))
+`, payload.TeamName, pos, ownerPos, owner.UserID, owner.TeamName); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &Ownership{TeamName: payload.TeamName, Rules: rules}, nil
+}
+
+// TeamOwnership returns the ownership rules of a team in file order.
+func (s *Store) TeamOwnership(ctx context.Context, teamName string) (*Ownership, error) {
+	if err := requireTeam(ctx, s.db, teamName); err != nil {
+		return nil, err
+	}
+	rules, err := loadOwnershipRules(ctx, s.db, teamName)
+	if err != nil {
+		return nil, err
+	}
+	return &Ownership{TeamName: teamName, Rules: rules}, nil
+}
+
+func requireTeam(ctx context.Context, q querier, teamName string) error {
+	var exists bool
+	if err := q.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM teams WHERE name=$1)`, teamName).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrTeamNotFound
+	}
+	return nil
+}
+
+func validateOwners(ctx context.Context, tx *sql.Tx, owners []Owner) error {
+	for _, owner := range owners {
+		query, id, kind := `SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)`, owner.UserID, "user"
+		if owner.TeamName != "" {
+			query, id, kind = `SELECT EXISTS(SELECT 1 FROM teams WHERE name=$1)`, owner.TeamName, "team"
+		}
+		var exists bool
+		if err := tx.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: unknown %s %q", ErrInvalidOwnership, kind, id)
+		}
+	}
+	return nil
+}
+
+func loadOwnershipRules(ctx context.Context, q querier, teamName string) ([]OwnershipRule, error) {
+	rows, err := q.QueryContext(ctx, `
+SELECT r.position, r.pattern, COALESCE(o.user_id, ''), COALESCE(o.owner_team, '')
+FROM ownership_rules r
+LEFT JOIN ownership_owners o ON o.team_name = r.team_name AND o.position = r.position
+WHERE r.team_name=$1
+ORDER BY r.position, o.owner_position
+`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	rules := make([]OwnershipRule, 0)
+	last := -1
+	for rows.Next() {
+		var pos int
+		var pattern string
+		var owner Owner
+		if err := rows.Scan(&pos, &pattern, &owner.UserID, &owner.TeamName); err != nil {
+			return nil, err
+		}
+		if pos != last {
+			rules = append(rules, OwnershipRule{Pattern: pattern, Owners: make([]Owner, 0)})
+			last = pos
+		}
+		if owner.UserID != "" || owner.TeamName != "" {
+			rules[len(rules)-1].Owners = append(rules[len(rules)-1].Owners, owner)
+		}
+	}
+	return rules, rows.Err()
+}
+
+// compileOwnershipRules compiles the rule patterns once per load, so matching
+// every changed file does not rebuild them. A stored pattern that does not
+// compile is left nil and never matches.
+func compileOwnershipRules(rules []OwnershipRule) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		patterns[i], _ = compileOwnershipPattern(rule.Pattern)
+	}
+	return patterns
+}
+
+// ownerRuleFor returns the index of the last pattern matching path, or -1.
+func ownerRuleFor(patterns []*regexp.Regexp, path string) int {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "./"), "/")
+	for i := len(patterns) - 1; i >= 0; i-- {
+		if patterns[i] != nil && patterns[i].MatchString(path) {
+			return i
+		}
+	}
+	return -1
+}
+
+// compileOwnershipPattern implements the CODEOWNERS subset of gitignore globs:
+// "*" stays within a path segment, "**" crosses segments, a pattern with a
+// leading or inner "/" is anchored at the repository root, and a pattern
+// naming a directory matches everything below it, except for "dir/*" which
+// only matches direct children. Character classes and escapes are rejected
+// rather than matched literally.
+func compileOwnershipPattern(pattern string) (*regexp.Regexp, error) {
+	if strings.ContainsAny(pattern, `[]\`) {
+		return nil, fmt.Errorf("character classes and escapes are not supported in %q", pattern)
+	}
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	switch {
+	case dirOnly:
+		expr.WriteString("/.*$")
+	case strings.HasSuffix(pattern, "/*"):
+		expr.WriteString("$")
+	default:
+		expr.WriteString("(?:/.*)?$")
+	}
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// pickOwners assigns, for every ownership rule matched by the changed files,
+// one eligible owner unless an owner is already picked or among assigned, the
+// reviewers already on the PR. Rules are handled in the order their files are
+// listed until limit is reached; files whose owners cannot be assigned are
+// reported in uncoveredPaths. Owners holding the role of policy count towards
+// the team role policy.
+func (s *Store) pickOwners(
+	ctx context.Context,
+	q querier,
+	settings TeamSettings,
+	author string,
+	files []string,
+	assigned map[string]struct{},
+	policy selectionRules,
+	limit int,
+) (selection, error) {
+	if len(files) == 0 {
+		return selection{}, nil
+	}
+	rules, err := loadOwnershipRules(ctx, q, settings.TeamName)
+	if err != nil || len(rules) == 0 {
+		return selection{}, err
+	}
+	patterns := compileOwnershipRules(rules)
+	order := make([]int, 0)
+	pathsByRule := make(map[int][]string)
+	for _, file := range files {
+		idx := ownerRuleFor(patterns, file)
+		if idx < 0 || len(rules[idx].Owners) == 0 {
+			continue
+		}
+		if _, ok := pathsByRule[idx]; !ok {
+			order = append(order, idx)
+		}
+		pathsByRule[idx] = append(pathsByRule[idx], file)
+	}
+	if len(order) == 0 {
+		return selection{}, nil
+	}
+
+	pools := newOwnerPools(author, assigned, policy.excluded)
+	strategy := s.strategyFor(settings.ReviewerStrategy)
+	explanation := SelectionExplanation{
+		TeamName: settings.TeamName,
+		Strategy: strategy,
+		Owners:   true,
+		Pool:     make([]string, 0),
+		Excluded: make([]Exclusion, 0),
+	}
+	var result selection
+	for _, idx := range order {
+		candidates, onPR, err := pools.candidates(ctx, q, rules[idx].Owners)
+		if err != nil {
+			return selection{}, err
+		}
+		picked := slices.ContainsFunc(candidates, func(c Candidate) bool {
+			return slices.Contains(result.reviewers, c.UserID)
+		})
+		if onPR || picked {
+			continue
+		}
+		var chosen []string
+		if len(result.reviewers) < limit {
+			chosen = s.selectors[strategy].Select(candidates, 1)
+		}
+		if len(chosen) == 0 {
+			result.uncoveredPaths = append(result.uncoveredPaths, pathsByRule[idx]...)
+			continue
+		}
+		result.reviewers = append(result.reviewers, chosen[0])
+		if policy.roleCount > 0 && slices.ContainsFunc(candidates, func(c Candidate) bool {
+			return c.UserID == chosen[0] && roleAtLeast(c.Role, policy.role)
+		}) {
+			explanation.PolicyReviewers = append(explanation.PolicyReviewers, chosen[0])
+		}
+	}
+	explanation.Pool, explanation.Excluded = pools.report()
+	explanation.Selected = append([]string{}, result.reviewers...)
+	result.fromOwners = append([]string{}, result.reviewers...)
+	result.policyReviewers = len(explanation.PolicyReviewers)
+	result.explanation = []SelectionExplanation{explanation}
+	return result, nil
+}
+
+// ownerPools resolves owners to eligible candidates, loading each involved
+// team once.
+type ownerPools struct {
+	author   string
+	assigned map[string]struct{}
+	byAuthor map[string]struct{}
+	teams    map[string][]teamMember
+	capacity map[string]int
+	userTeam map[string]string
+	pool     []string
+	excluded []Exclusion
+	seen     map[string]struct{}
+}
+
+func newOwnerPools(author string, assigned, byAuthor map[string]struct{}) *ownerPools {
+	return &ownerPools{
+		author:   author,
+		assigned: assigned,
+		byAuthor: byAuthor,
+		teams:    make(map[string][]teamMember),
+		capacity: make(map[string]int),
+		userTeam: make(map[string]string),
+		seen:     make(map[string]struct{}),
+	}
+}
+
+// candidates returns the eligible owners and whether one of the owners is
+// already assigned to the PR.
+func (p *ownerPools) candidates(ctx context.Context, q querier, owners []Owner) ([]Candidate, bool, error) {
+	out := make([]Candidate, 0)
+	added := make(map[string]struct{})
+	onPR := false
+	for _, owner := range owners {
+		team := owner.TeamName
+		if owner.UserID != "" {
+			var err error
+			if team, err = p.teamOf(ctx, q, owner.UserID); err != nil {
+				return nil, false, err
+			}
+		}
+		members, err := p.members(ctx, q, team)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, m := range members {
+			if owner.UserID != "" && m.UserID != owner.UserID {
+				continue
+			}
+			if _, ok := p.assigned[m.UserID]; ok {
+				onPR = true
+			}
+			reason := exclusionReason(m, p.author, p.assigned, p.capacity[team])
+			if _, ok := p.byAuthor[m.UserID]; ok && reason == "" {
+				reason = ExcludedByAuthor
+			}
+			p.record(m.UserID, reason)
+			if _, ok := added[m.UserID]; reason == "" && !ok {
+				added[m.UserID] = struct{}{}
+				out = append(out, m.Candidate)
+			}
+		}
+	}
+	return out, onPR, nil
+}
+
+func (p *ownerPools) teamOf(ctx context.Context, q querier, userID string) (string, error) {
+	if team, ok := p.userTeam[userID]; ok {
+		return team, nil
+	}
+	var team sql.NullString
+	err := q.QueryRowContext(ctx, `SELECT team_name FROM users WHERE user_id=$1`, userID).Scan(&team)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+	p.userTeam[userID] = team.String
+	return team.String, nil
+}
+
+func (p *ownerPools) members(ctx context.Context, q querier, team string) ([]teamMember, error) {
+	if team == "" {
+		return nil, nil
+	}
+	if members, ok := p.teams[team]; ok {
+		return members, nil
+	}
+	settings, err := loadTeamSettings(ctx, q, team)
+	if err != nil {
+		return nil, err
+	}
+	members, err := loadTeamMembers(ctx, q, team)
+	if err != nil {
+		return nil, err
+	}
+	p.teams[team] = members
+	p.capacity[team] = settings.DefaultMaxOpenReviews
+	return members, nil
+}
+
+func (p *ownerPools) record(userID, reason string) {
+	if _, ok := p.seen[userID]; ok {
+		return
+	}
+	p.seen[userID] = struct{}{}
+	if reason == "" {
+		p.pool = append(p.pool, userID)
+		return
+	}
+	p.excluded = append(p.excluded, Exclusion{UserID: userID, Reason: reason})
+}
+
+func (p *ownerPools) report() ([]string, []Exclusion) {
+	pool, excluded := p.pool, p.excluded
+	if pool == nil {
+		pool = make([]string, 0)
+	}
+	if excluded == nil {
+		excluded = make([]Exclusion, 0)
+	}
+	return pool, excluded
+}
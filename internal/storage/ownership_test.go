@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	rules, err := ParseCodeOwners(`# comment
+*.go     @u1 @org/backend  # trailing comment
+
+/docs/   docs-writer
+/vendor/
+`)
+	if err != nil {
+		t.Fatalf("ParseCodeOwners error: %v", err)
+	}
+	want := []OwnershipRule{
+		{Pattern: "*.go", Owners: []Owner{{UserID: "u1"}, {TeamName: "backend"}}},
+		{Pattern: "/docs/", Owners: []Owner{{UserID: "docs-writer"}}},
+		{Pattern: "/vendor/", Owners: []Owner{}},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Fatalf("rules = %+v, want %+v", rules, want)
+	}
+	for _, text := range []string{"!*.md @u1", "*.go @u1\n*.[ch] @u2", "\\#notes @u1"} {
+		if _, err := ParseCodeOwners(text); !errors.Is(err, ErrInvalidOwnership) {
+			t.Fatalf("%q: expected ErrInvalidOwnership, got %v", text, err)
+		}
+	}
+}
+
+func TestCompileOwnershipPattern(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*", "any/file.txt", true},
+		{"*.js", "web/app.js", true},
+		{"*.js", "web/app.ts", false},
+		{"/build/logs/", "build/logs/a/b.log", true},
+		{"/build/logs/", "src/build/logs/a.log", false},
+		{"docs/*", "docs/intro.md", true},
+		{"docs/*", "docs/guide/intro.md", false},
+		{"apps/", "src/apps/main.go", true},
+		{"**/logs", "deep/nested/logs/x.log", true},
+		{"/scripts/**/*.sh", "scripts/a/b/run.sh", true},
+		{"internal/storage", "internal/storage/postgres.go", true},
+		{"internal/storage", "cmd/internal/storage/x.go", false},
+	}
+	for _, tc := range cases {
+		re, err := compileOwnershipPattern(tc.pattern)
+		if err != nil {
+			t.Fatalf("compile %q: %v", tc.pattern, err)
+		}
+		if got := re.MatchString(tc.path); got != tc.want {
+			t.Errorf("match(%q, %q) = %v, want %v", tc.pattern, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestOwnerRuleForLastMatchWins(t *testing.T) {
+	rules := []OwnershipRule{
+		{Pattern: "*", Owners: []Owner{{UserID: "u1"}}},
+		{Pattern: "*.go", Owners: []Owner{{UserID: "u2"}}},
+		{Pattern: "/vendor/"},
+		{Pattern: "[legacy]"},
+	}
+	patterns := compileOwnershipRules(rules)
+	if got := ownerRuleFor(patterns, "cmd/main.go"); got != 1 {
+		t.Fatalf("main.go rule = %d, want 1", got)
+	}
+	if got := ownerRuleFor(patterns, "./vendor/lib.go"); got != 2 {
+		t.Fatalf("vendor rule = %d, want 2", got)
+	}
+	if got := ownerRuleFor(patterns[1:2], "README.md"); got != -1 {
+		t.Fatalf("README rule = %d, want -1", got)
+	}
+	if patterns[3] != nil {
+		t.Fatalf("stored pattern %q should never match", rules[3].Pattern)
+	}
+}
+
+func TestSetOwnershipRejectsMalformedPattern(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	_, err := store.SetOwnership(context.Background(), OwnershipPayload{TeamName: teamBackend, CodeOwners: "src/[a-z]*.go @u1"})
+	if !errors.Is(err, ErrInvalidOwnership) {
+		t.Fatalf("expected ErrInvalidOwnership, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSetOwnershipRejectsUnknownOwner(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).WithArgs(teamBackend).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM users WHERE user_id=`).WithArgs("ghost").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+
+	_, err := store.SetOwnership(context.Background(), OwnershipPayload{TeamName: teamBackend, CodeOwners: "* @ghost"})
+	if !errors.Is(err, ErrInvalidOwnership) {
+		t.Fatalf("expected ErrInvalidOwnership, got %v", err)
+	}
+}
+
+func TestSetOwnershipReplacesRules(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).WithArgs(teamBackend).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).WithArgs("platform").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(`DELETE FROM ownership_rules WHERE team_name=`).WithArgs(teamBackend).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`INSERT INTO ownership_rules`).WithArgs(teamBackend, 0, "docs/").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO ownership_owners`).WithArgs(teamBackend, 0, 0, "", "platform").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	got, err := store.SetOwnership(context.Background(), OwnershipPayload{TeamName: teamBackend, CodeOwners: "docs/ @org/platform"})
+	if err != nil {
+		t.Fatalf("SetOwnership error: %v", err)
+	}
+	if len(got.Rules) != 1 || got.Rules[0].Owners[0].TeamName != "platform" {
+		t.Fatalf("unexpected ownership: %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCreatePRAssignsOwnersFirst(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 1, 3)
+	mock.ExpectQuery(`SELECT r.position, r.pattern`).WithArgs(teamBackend).
+		WillReturnRows(sqlmock.NewRows([]string{"position", "pattern", "user_id", "owner_team"}).
+			AddRow(0, "*.go", "u2", "").
+			AddRow(1, "docs/", "", "platform").
+			AddRow(2, "/infra/", "", "infra"))
+	mock.ExpectQuery(`SELECT team_name FROM users WHERE user_id=`).WithArgs("u2").
+		WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow(teamBackend))
+	expectTeamSettings(mock, teamBackend)
+	expectCandidateRows(mock, teamBackend, "u1", "u2", "u3")
+	expectTeamSettings(mock, "platform")
+	expectCandidateRows(mock, "platform", "p1")
+	expectTeamSettings(mock, "infra")
+	expectCandidateRows(mock, "infra")
+	// The remaining slot is filled by the author's team as usual.
+	expectCandidateRows(mock, teamBackend, "u1", "u2")
+	mock.ExpectExec(`INSERT INTO pull_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	for i := 0; i < 3; i++ {
+		mock.ExpectExec(`INSERT INTO assigned_reviewers`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO assignment_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	pr, err := store.CreatePR(context.Background(), CreatePRPayload{
+		ID: "pr1", Name: "feature", Author: authorID,
+		ChangedFiles: []string{"cmd/main.go", "docs/intro.md", "internal/db.go", "infra/main.tf"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePR error: %v", err)
+	}
+	if want := []string{"u2", "p1", "u1"}; !reflect.DeepEqual(pr.AssignedReviewers, want) {
+		t.Fatalf("reviewers = %v, want %v", pr.AssignedReviewers, want)
+	}
+	if want := []string{"u2", "p1"}; !reflect.DeepEqual(pr.OwnerReviewers, want) {
+		t.Fatalf("owner reviewers = %v, want %v", pr.OwnerReviewers, want)
+	}
+	if want := []string{"infra/main.tf"}; !reflect.DeepEqual(pr.UncoveredPaths, want) {
+		t.Fatalf("uncovered paths = %v, want %v", pr.UncoveredPaths, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"math/rand"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,9 +13,12 @@ import (
 	"go.uber.org/zap"
 )
 
+// Pull request statuses; lifecycle.go defines the transitions between them.
 const (
+	StatusDraft  = "DRAFT"
 	StatusOpen   = "OPEN"
 	StatusMerged = "MERGED"
+	StatusClosed = "CLOSED"
 )
 
 var (
@@ -28,23 +31,65 @@ var (
 	ErrPRNotFound    = errors.New("pr not found")
 	ErrTeamNotFound  = errors.New("team not found")
 	ErrInvalidStatus = errors.New("invalid status")
+
+	ErrInvalidReviewerCount = errors.New("reviewer count out of team bounds")
+	ErrAbsenceNotFound      = errors.New("absence not found")
+	ErrInvalidAbsence       = errors.New("absence must end after it starts")
+	ErrInvalidCapacity      = errors.New("review capacity must not be negative")
+	ErrInvalidFallback      = errors.New("invalid fallback team")
+	ErrInvalidPairingWindow = errors.New("pairing window must not be negative")
+	ErrInvalidTag           = errors.New("tags must not be empty")
+	ErrTagsUncovered        = errors.New("required tags not covered by any reviewer")
+	ErrInvalidOwnership     = errors.New("invalid ownership rules")
+	ErrInvalidRole          = errors.New("unknown reviewer role")
+	ErrInvalidPolicy        = errors.New("invalid reviewer role policy")
+	ErrPolicyUnsatisfiable  = errors.New("reviewer role policy cannot be satisfied")
+	ErrInvalidReviewers     = errors.New("invalid requested or excluded reviewers")
+	ErrIneligibleReviewer   = errors.New("replacement reviewer is not eligible")
+	ErrAlreadyAssigned      = errors.New("reviewer already assigned")
+	ErrInvalidVerdict       = errors.New("unknown review verdict")
+	ErrInvalidApprovals     = errors.New("required approvals out of range")
+	ErrNotEnoughApprovals   = errors.New("not enough approvals to merge")
+	ErrInvalidFilter        = errors.New("invalid pull request filter")
+	ErrMemberOfOtherTeam    = errors.New("user belongs to another team")
+	ErrNotTeamMember        = errors.New("user is not a member of the team")
+	ErrMemberHasOpenPRs     = errors.New("member authors open pull requests")
+	ErrTeamNotEmpty         = errors.New("team still has members")
 )
 
 type User struct {
-	ID       string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	ID             string `json:"user_id"`
+	Username       string `json:"username"`
+	TeamName       string `json:"team_name"`
+	IsActive       bool   `json:"is_active"`
+	MaxOpenReviews int    `json:"max_open_reviews,omitempty"`
+	Role           string `json:"role,omitempty"`
 }
 
 type PullRequest struct {
-	ID                string     `json:"pull_request_id"`
-	Name              string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            string     `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         time.Time  `json:"createdAt"`
-	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	ID                string   `json:"pull_request_id"`
+	Name              string   `json:"pull_request_name"`
+	AuthorID          string   `json:"author_id"`
+	Status            string   `json:"status"`
+	AssignedReviewers []string `json:"assigned_reviewers"`
+	// Reviews are the verdicts submitted by the current reviewers.
+	Reviews []Review `json:"reviews,omitempty"`
+	// FallbackReviewers lists reviewers of this response that were taken from fallback teams.
+	FallbackReviewers []string `json:"fallback_reviewers,omitempty"`
+	// UncoveredTags lists required tags no assigned reviewer has.
+	UncoveredTags []string `json:"uncovered_tags,omitempty"`
+	// OwnerReviewers were assigned as owners of changed files.
+	OwnerReviewers []string `json:"owner_reviewers,omitempty"`
+	// UncoveredPaths are changed files whose owners could not be assigned.
+	UncoveredPaths []string `json:"uncovered_paths,omitempty"`
+	// SkippedReviewers are requested reviewers left out because they are inactive.
+	SkippedReviewers []string `json:"skipped_reviewers,omitempty"`
+	// MissingReviewers is how many reviewers the PR lacks to reach the team's min_reviewers.
+	MissingReviewers int `json:"missing_reviewers,omitempty"`
+	// Explanation is filled only when the caller asked for it.
+	Explanation []SelectionExplanation `json:"explanation,omitempty"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	MergedAt    *time.Time             `json:"mergedAt,omitempty"`
 }
 
 type PullRequestShort struct {
@@ -54,15 +99,37 @@ type PullRequestShort struct {
 	Status   string `json:"status"`
 }
 
+func (pr PullRequest) Short() PullRequestShort {
+	return PullRequestShort{ID: pr.ID, Name: pr.Name, AuthorID: pr.AuthorID, Status: pr.Status}
+}
+
 type TeamPayload struct {
-	TeamName string         `json:"team_name"`
-	Members  []TeamUpserted `json:"members"`
+	TeamName         string `json:"team_name"`
+	ReviewerStrategy string `json:"reviewer_strategy,omitempty"`
+	MinReviewers     *int   `json:"min_reviewers,omitempty"`
+	MaxReviewers     *int   `json:"max_reviewers,omitempty"`
+	// DefaultMaxOpenReviews caps open reviews of members without a personal limit; 0 means no cap.
+	DefaultMaxOpenReviews int `json:"default_max_open_reviews,omitempty"`
+	// FallbackTeams are asked, in order, for reviewers the team cannot provide itself.
+	FallbackTeams []string       `json:"fallback_teams,omitempty"`
+	Members       []TeamUpserted `json:"members"`
 }
 
 type TeamUpserted struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID       string `json:"user_id"`
+	Username     string `json:"username"`
+	IsActive     bool   `json:"is_active"`
+	ReviewWeight int    `json:"review_weight,omitempty"`
+	// MaxOpenReviews is the personal limit; 0 falls back to the team default.
+	MaxOpenReviews int `json:"max_open_reviews,omitempty"`
+	// OpenReviews and Capacity are reported by the API and ignored on input.
+	// Capacity is the effective limit, 0 when the member is not capped.
+	OpenReviews int `json:"open_reviews"`
+	Capacity    int `json:"capacity,omitempty"`
+	// Role is member (the default), senior or lead.
+	Role string `json:"role,omitempty"`
+	// Skills replaces the member's skill tags when present; [] clears them.
+	Skills []string `json:"skills,omitempty"`
 }
 
 type SetActivePayload struct {
@@ -70,43 +137,136 @@ type SetActivePayload struct {
 	IsActive bool   `json:"is_active"`
 }
 
+// SetMaxOpenReviewsPayload sets the personal capacity; 0 clears it.
+type SetMaxOpenReviewsPayload struct {
+	UserID         string `json:"user_id"`
+	MaxOpenReviews int    `json:"max_open_reviews"`
+}
+
 type CreatePRPayload struct {
 	ID     string `json:"pull_request_id"`
 	Name   string `json:"pull_request_name"`
 	Author string `json:"author_id"`
+	// ReviewersCount overrides the team default; it must stay within the team bounds.
+	ReviewersCount *int `json:"reviewers_count,omitempty"`
+	// RequiredTags are skills the reviewers should cover, one reviewer per tag at least.
+	RequiredTags []string `json:"required_tags,omitempty"`
+	// ChangedFiles are repository paths touched by the PR; owners of these
+	// paths are assigned before the remaining slots are filled.
+	ChangedFiles []string `json:"changed_files,omitempty"`
+	// RequireTags fails the request with ErrTagsUncovered instead of assigning
+	// reviewers that leave some tag uncovered.
+	RequireTags bool `json:"require_tags,omitempty"`
+	// RequestedReviewers are assigned first whenever they are active, from any
+	// team; they take up slots of the reviewer count.
+	RequestedReviewers []string `json:"requested_reviewers,omitempty"`
+	// ExcludedReviewers are never picked for this PR.
+	ExcludedReviewers []string `json:"excluded_reviewers,omitempty"`
+	// Draft creates the PR in DRAFT status without reviewers; they are picked
+	// when the PR is marked ready.
+	Draft bool `json:"draft,omitempty"`
+	// Explain asks for the selection explanation in the response.
+	Explain bool `json:"-"`
 }
 
 type MergePayload struct {
 	ID string `json:"pull_request_id"`
 }
 
+type ClosePayload struct {
+	ID string `json:"pull_request_id"`
+}
+
 type ReassignPayload struct {
 	PRID string `json:"pull_request_id"`
 	Old  string `json:"old_user_id"`
+	// New is the replacement chosen by the caller; empty lets the team strategy pick.
+	New     string `json:"new_user_id,omitempty"`
+	Explain bool   `json:"-"`
+}
+
+// ReassignmentSummary reports how open reviews of deactivated users were redistributed.
+type ReassignmentSummary struct {
+	Reassigned []Reassignment `json:"reassigned"`
+	// Unfilled lists PRs that lost a reviewer without getting a replacement.
+	Unfilled []string `json:"unfilled"`
+}
+
+type Reassignment struct {
+	PRID      string `json:"pull_request_id"`
+	OldUserID string `json:"old_user_id"`
+	NewUserID string `json:"new_user_id"`
 }
 
 type Stats struct {
 	AssignmentsPerUser map[string]int `json:"assignments_per_user"`
 	OpenPRs            int            `json:"open_prs"`
 	MergedPRs          int            `json:"merged_prs"`
+	DraftPRs           int            `json:"draft_prs"`
+	ClosedPRs          int            `json:"closed_prs"`
 }
 
 type Store struct {
-	db     *sql.DB
-	rnd    *rand.Rand
-	logger *zap.SugaredLogger
+	db              *sql.DB
+	rnd             Random
+	seed            int64
+	logger          *zap.SugaredLogger
+	selectors       map[string]ReviewerSelector
+	defaultStrategy string
+}
+
+// Option customizes a Store created by NewStore.
+type Option func(*Store)
+
+// WithDefaultStrategy sets the selection strategy used by teams that do not pin their own.
+func WithDefaultStrategy(name string) Option {
+	return func(s *Store) {
+		if IsKnownStrategy(name) {
+			s.defaultStrategy = name
+		}
+	}
+}
+
+// WithRandom makes reviewer selection draw from rnd, which must be safe for concurrent use.
+// WithRandom and WithSeed both set the selection randomness, so whichever comes
+// last in the option list wins; a nil rnd leaves the previous choice in place.
+func WithRandom(rnd Random) Option {
+	return func(s *Store) {
+		if rnd != nil {
+			s.rnd = rnd
+		}
+	}
 }
 
-func NewStore(db *sql.DB, logger *zap.SugaredLogger) *Store {
+// WithSeed fixes the seed of the selection randomness so that the same sequence
+// of requests yields the same assignments. It replaces a source set by an
+// earlier WithRandom and is itself replaced by a later one.
+func WithSeed(seed int64) Option {
+	return func(s *Store) {
+		s.seed = seed
+		s.rnd = nil
+	}
+}
+
+func NewStore(db *sql.DB, logger *zap.SugaredLogger, opts ...Option) *Store {
 	if logger == nil {
 		logger = zap.NewNop().Sugar()
 	}
-	return &Store{
-		db:     db,
-		logger: logger,
-		// pseudo-randomness is fine for reviewer selection
-		rnd: rand.New(rand.NewSource(time.Now().UnixNano())), //nolint:gosec
+	s := &Store{
+		db:              db,
+		logger:          logger,
+		seed:            time.Now().UnixNano(),
+		defaultStrategy: StrategyRandom,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	if s.rnd == nil {
+		s.rnd = NewSeededRandom(s.seed)
+		logger.Infow("reviewer selection seeded", "seed", s.seed)
+	}
+	s.selectors = newSelectors(s.rnd)
+	return s
 }
 
 func (s *Store) AddTeam(ctx context.Context, payload TeamPayload) (TeamPayload, error) {
@@ -135,60 +295,195 @@ func (s *Store) addTeamOnce(ctx context.Context, payload TeamPayload) (TeamPaylo
 		}
 	}()
 
-	if _, err := tx.ExecContext(ctx, `INSERT INTO teams(name) VALUES ($1)`, payload.TeamName); err != nil {
+	minReviewers, maxReviewers := DefaultMinReviewers, DefaultMaxReviewers
+	if payload.MinReviewers != nil {
+		minReviewers = *payload.MinReviewers
+	}
+	if payload.MaxReviewers != nil {
+		maxReviewers = *payload.MaxReviewers
+	}
+	if err := validateReviewerBounds(minReviewers, maxReviewers); err != nil {
+		return TeamPayload{}, err
+	}
+	if payload.DefaultMaxOpenReviews < 0 {
+		return TeamPayload{}, ErrInvalidCapacity
+	}
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO teams(name, reviewer_strategy, min_reviewers, max_reviewers, default_max_open_reviews)
+VALUES ($1, NULLIF($2, ''), $3, $4, NULLIF($5, 0))`,
+		payload.TeamName,
+		payload.ReviewerStrategy,
+		minReviewers,
+		maxReviewers,
+		payload.DefaultMaxOpenReviews,
+	); err != nil {
 		if isUniqueViolation(err) {
 			return TeamPayload{}, ErrTeamExists
 		}
 		return TeamPayload{}, err
 	}
+	if len(payload.FallbackTeams) > 0 {
+		if err := replaceFallbackTeams(ctx, tx, payload.TeamName, payload.FallbackTeams); err != nil {
+			return TeamPayload{}, err
+		}
+	}
+	if err := upsertTeamMembers(ctx, tx, payload.TeamName, payload.Members); err != nil {
+		return TeamPayload{}, err
+	}
+	team, err := buildTeam(ctx, tx, payload.TeamName)
+	if err != nil {
+		return TeamPayload{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return TeamPayload{}, err
+	}
+	return team, nil
+}
+
+// upsertTeamMembers creates the members or updates them in place, moving
+// existing users into teamName. Members without a user_id are ignored.
+func upsertTeamMembers(ctx context.Context, tx *sql.Tx, teamName string, members []TeamUpserted) error {
 	unique := make(map[string]TeamUpserted)
-	for _, m := range payload.Members {
+	for _, m := range members {
 		if m.UserID == "" {
 			continue
 		}
 		unique[m.UserID] = m
 	}
 	for _, m := range unique {
-		_, err := tx.ExecContext(ctx, `
-INSERT INTO users(user_id, username, is_active, team_name)
-VALUES ($1,$2,$3,$4)
+		weight := m.ReviewWeight
+		if weight <= 0 {
+			weight = defaultReviewWeight
+		}
+		if m.MaxOpenReviews < 0 {
+			return ErrInvalidCapacity
+		}
+		role, err := normalizeRole(m.Role)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO users(user_id, username, is_active, team_name, review_weight, max_open_reviews, role)
+VALUES ($1,$2,$3,$4,$5,NULLIF($6, 0),$7)
 ON CONFLICT (user_id) DO UPDATE
 SET username = EXCLUDED.username,
     is_active = EXCLUDED.is_active,
-    team_name = EXCLUDED.team_name
-`, m.UserID, m.Username, m.IsActive, payload.TeamName)
+    team_name = EXCLUDED.team_name,
+    review_weight = EXCLUDED.review_weight,
+    max_open_reviews = EXCLUDED.max_open_reviews,
+    role = EXCLUDED.role
+`, m.UserID, m.Username, m.IsActive, teamName, weight, m.MaxOpenReviews, role)
 		if err != nil {
-			return TeamPayload{}, err
+			return err
+		}
+		if m.Skills != nil {
+			skills, err := normalizeTags(m.Skills)
+			if err != nil {
+				return err
+			}
+			if err := replaceUserSkills(ctx, tx, m.UserID, skills); err != nil {
+				return err
+			}
 		}
 	}
-	team, err := buildTeam(ctx, tx, payload.TeamName)
+	return nil
+}
+
+func (s *Store) GetTeam(ctx context.Context, teamName string) (TeamPayload, error) {
+	return buildTeam(ctx, s.db, teamName)
+}
+
+// SetUserActive toggles the user's activity flag. Deactivation also hands the
+// user's OPEN reviews over to active teammates within the same transaction.
+func (s *Store) SetUserActive(ctx context.Context, payload SetActivePayload) (*User, *ReassignmentSummary, error) {
+	if payload.IsActive {
+		u, err := setUserActive(ctx, s.db, payload)
+		return u, nil, err
+	}
+	for attempts := 0; attempts < 3; attempts++ {
+		u, summary, err := s.deactivateUserOnce(ctx, payload)
+		if err == nil {
+			return u, summary, nil
+		}
+		if isSerializationError(err) && attempts < 2 {
+			time.Sleep(time.Duration(attempts+1) * 10 * time.Millisecond)
+			continue
+		}
+		return nil, nil, err
+	}
+	return nil, nil, fmt.Errorf("unreachable")
+}
+
+func (s *Store) deactivateUserOnce(ctx context.Context, payload SetActivePayload) (*User, *ReassignmentSummary, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
-		return TeamPayload{}, err
+		return nil, nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	u, err := setUserActive(ctx, tx, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	assignments, err := s.fetchUserAssignments(ctx, tx, u.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	summary, err := s.reassignAfterDeactivation(ctx, tx, u.TeamName, assignments, ReasonUserDeactivated)
+	if err != nil {
+		return nil, nil, err
 	}
 	if err := tx.Commit(); err != nil {
-		return TeamPayload{}, err
+		return nil, nil, err
 	}
-	return team, nil
+	return u, summary, nil
 }
 
-func (s *Store) GetTeam(ctx context.Context, teamName string) (TeamPayload, error) {
-	return buildTeam(ctx, s.db, teamName)
+// SetMaxOpenReviews sets the personal limit of concurrent open reviews. It only
+// affects future selections; reviews above the new limit stay assigned.
+func (s *Store) SetMaxOpenReviews(ctx context.Context, payload SetMaxOpenReviewsPayload) (*User, error) {
+	if payload.MaxOpenReviews < 0 {
+		return nil, ErrInvalidCapacity
+	}
+	row := s.db.QueryRowContext(ctx, `
+UPDATE users
+SET max_open_reviews = NULLIF($2, 0)
+WHERE user_id = $1
+RETURNING user_id, username, team_name, is_active, COALESCE(max_open_reviews, 0)
+`, payload.UserID, payload.MaxOpenReviews)
+	var u User
+	var team sql.NullString
+	if err := row.Scan(&u.ID, &u.Username, &team, &u.IsActive, &u.MaxOpenReviews); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	u.TeamName = team.String
+	return &u, nil
 }
 
-func (s *Store) SetUserActive(ctx context.Context, payload SetActivePayload) (*User, error) {
-	row := s.db.QueryRowContext(ctx, `
+func setUserActive(ctx context.Context, q querier, payload SetActivePayload) (*User, error) {
+	row := q.QueryRowContext(ctx, `
 UPDATE users
 SET is_active = $2
 WHERE user_id = $1
 RETURNING user_id, username, team_name, is_active
 `, payload.UserID, payload.IsActive)
 	var u User
-	if err := row.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+	var team sql.NullString
+	if err := row.Scan(&u.ID, &u.Username, &team, &u.IsActive); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
+	u.TeamName = team.String
 	return &u, nil
 }
 
@@ -229,80 +524,183 @@ func (s *Store) createPROnce(ctx context.Context, payload CreatePRPayload) (*Pul
 		return nil, ErrPRExists
 	}
 
-	var authorID, teamName string
-	if err := tx.QueryRowContext(ctx,
-		`SELECT user_id, team_name FROM users WHERE user_id=$1`, payload.Author).
-		Scan(&authorID, &teamName); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrUserNotFound
+	status := StatusOpen
+	var picked selection
+	if payload.Draft {
+		status = StatusDraft
+		if _, err := lookupAuthorTeam(ctx, tx, payload.Author); err != nil {
+			return nil, err
 		}
+	} else if picked, err = s.selectForNewPR(ctx, tx, payload); err != nil {
 		return nil, err
 	}
-	var teamExists bool
-	if err := tx.QueryRowContext(ctx,
-		`SELECT EXISTS(SELECT 1 FROM teams WHERE name=$1)`, teamName).
-		Scan(&teamExists); err != nil {
-		return nil, err
-	}
-	if !teamExists {
-		return nil, ErrTeamNotFound
+	candidates := picked.reviewers
+	if candidates == nil {
+		candidates = []string{}
 	}
 
-	candidates, err := s.pickCandidates(ctx, tx, teamName, payload.Author, nil, 2)
-	if err != nil {
-		return nil, err
-	}
-
-	// Валидация: статус должен быть только OPEN или MERGED (валидация на уровне приложения)
-	// Используем константу StatusOpen для гарантии корректности
+	// Валидация: допустимые статусы и переходы между ними задаёт
+	// lifecycle.go, БД дополнительно проверяет статус CHECK-ограничением
 	now := time.Now().UTC()
 	if _, err := tx.ExecContext(ctx, `
-INSERT INTO pull_requests(pr_id, pr_name, author_id, status, created_at)
-VALUES ($1,$2,$3,$4,$5)`, payload.ID, payload.Name, payload.Author, StatusOpen, now); err != nil {
+INSERT INTO pull_requests(pr_id, pr_name, author_id, team_name, status, created_at)
+VALUES ($1,$2,$3,(SELECT team_name FROM users WHERE user_id=$3),$4,$5)`,
+		payload.ID, payload.Name, payload.Author, status, now); err != nil {
 		return nil, err
 	}
 	for _, c := range candidates {
-		if _, err := tx.ExecContext(ctx,
-			`INSERT INTO assigned_reviewers(pr_id, user_id) VALUES ($1,$2)`, payload.ID, c); err != nil {
+		if err := s.assignReviewer(ctx, tx, payload.ID, c, ReasonPRCreated, payload.Author); err != nil {
 			return nil, err
 		}
 	}
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
-	return &PullRequest{
+	pr := &PullRequest{
 		ID:                payload.ID,
 		Name:              payload.Name,
 		AuthorID:          payload.Author,
-		Status:            StatusOpen,
+		Status:            status,
 		AssignedReviewers: candidates,
+		FallbackReviewers: picked.fromFallback,
+		UncoveredTags:     picked.uncoveredTags,
+		OwnerReviewers:    picked.fromOwners,
+		UncoveredPaths:    picked.uncoveredPaths,
+		SkippedReviewers:  picked.skipped,
+		MissingReviewers:  picked.missing,
 		CreatedAt:         now,
-	}, nil
+	}
+	if payload.Explain {
+		pr.Explanation = picked.explanation
+	}
+	return pr, nil
+}
+
+func lookupAuthorTeam(ctx context.Context, q querier, authorID string) (string, error) {
+	var userID string
+	var teamName sql.NullString
+	if err := q.QueryRowContext(ctx,
+		`SELECT user_id, team_name FROM users WHERE user_id=$1`, authorID).
+		Scan(&userID, &teamName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrUserNotFound
+		}
+		return "", err
+	}
+	if !teamName.Valid {
+		return "", fmt.Errorf("%w: author %q has no team", ErrTeamNotFound, authorID)
+	}
+	return teamName.String, nil
+}
+
+// selectForNewPR picks reviewers for a PR of payload.Author the way CreatePR does.
+func (s *Store) selectForNewPR(ctx context.Context, tx *sql.Tx, payload CreatePRPayload) (selection, error) {
+	teamName, err := lookupAuthorTeam(ctx, tx, payload.Author)
+	if err != nil {
+		return selection{}, err
+	}
+	settings, err := loadTeamSettings(ctx, tx, teamName)
+	if err != nil {
+		return selection{}, err
+	}
+	limit := settings.MaxReviewers
+	if payload.ReviewersCount != nil {
+		limit = *payload.ReviewersCount
+		if limit < settings.MinReviewers || limit > settings.MaxReviewers {
+			return selection{}, ErrInvalidReviewerCount
+		}
+	}
+	tags, err := normalizeTags(payload.RequiredTags)
+	if err != nil {
+		return selection{}, err
+	}
+	if err := validateReviewerRequests(payload.Author, payload.RequestedReviewers, payload.ExcludedReviewers); err != nil {
+		return selection{}, err
+	}
+	if len(payload.RequestedReviewers) > limit {
+		return selection{}, ErrInvalidReviewerCount
+	}
+	rules := selectionRules{
+		requiredTags: tags,
+		requireTags:  payload.RequireTags,
+		role:         settings.RequiredRole,
+		roleCount:    settings.RequiredRoleCount,
+		excluded:     userSet(payload.ExcludedReviewers),
+	}
+
+	requested, err := s.pickRequested(ctx, tx, settings, payload.RequestedReviewers, rules)
+	if err != nil {
+		return selection{}, err
+	}
+	rules.requiredTags = requested.uncoveredTags
+	rules.roleCount = max(0, rules.roleCount-requested.policyReviewers)
+	block := userSet(requested.reviewers)
+	owners, err := s.pickOwners(ctx, tx, settings, payload.Author, payload.ChangedFiles, block, rules,
+		limit-len(requested.reviewers))
+	if err != nil {
+		return selection{}, err
+	}
+	picked := requested.then(owners)
+	rules.roleCount = max(0, rules.roleCount-owners.policyReviewers)
+	rest := selection{uncoveredTags: rules.requiredTags}
+	if remaining := limit - len(picked.reviewers); remaining > 0 {
+		for _, id := range owners.reviewers {
+			block[id] = struct{}{}
+		}
+		rest, err = s.pickWithFallback(ctx, tx, settings, payload.Author, block, rules, remaining)
+		if err != nil {
+			return selection{}, err
+		}
+	} else if rules.roleCount > 0 {
+		return selection{}, ErrPolicyUnsatisfiable
+	} else if len(rules.requiredTags) > 0 && rules.requireTags {
+		return selection{}, ErrTagsUncovered
+	}
+	picked = picked.then(rest)
+	picked.missing = max(0, settings.MinReviewers-len(picked.reviewers))
+	return picked, nil
 }
 
 func (s *Store) MergePR(ctx context.Context, id string) (*PullRequest, error) {
-	// Валидация: используем константу StatusMerged для гарантии корректности
-	// Валидация на уровне приложения, а не БД
-	row := s.db.QueryRowContext(ctx, `
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	_, status, err := lockPR(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(status, StatusMerged); err != nil {
+		return nil, err
+	}
+	if status == StatusOpen {
+		if err := checkApprovals(ctx, tx, id); err != nil {
+			return nil, err
+		}
+	}
+	// merged_at is kept on repeated merges, merging is idempotent
+	if _, err := tx.ExecContext(ctx, `
 UPDATE pull_requests
 SET status = $2,
     merged_at = COALESCE(merged_at, NOW())
 WHERE pr_id = $1
-RETURNING pr_id, pr_name, author_id, status, created_at, merged_at
-`, id, StatusMerged)
-	var pr PullRequest
-	if err := row.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrPRNotFound
-		}
+`, id, StatusMerged); err != nil {
 		return nil, err
 	}
-	reviewers, err := s.listReviewers(ctx, pr.ID)
+	pr, err := s.fetchPR(ctx, tx, id)
 	if err != nil {
 		return nil, err
 	}
-	pr.AssignedReviewers = reviewers
-	return &pr, nil
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return pr, nil
 }
 
 func (s *Store) Reassign(ctx context.Context, payload ReassignPayload) (*PullRequest, string, error) {
@@ -346,10 +744,24 @@ func (s *Store) reassignOnce(ctx context.Context, payload ReassignPayload) (*Pul
 	}
 
 	block := s.buildBlocklist(payload, prMeta.currentReviewers, prMeta.authorID)
-	candidates, err := s.pickCandidates(ctx, tx, reviewerTeam, "", block, 1)
+	settings, err := loadTeamSettings(ctx, tx, reviewerTeam)
+	if err != nil {
+		return nil, "", err
+	}
+	rules, err := reassignPolicy(ctx, tx, payload.PRID, payload.Old)
 	if err != nil {
 		return nil, "", err
 	}
+	var picked selection
+	if payload.New != "" {
+		picked, err = s.pickReplacement(ctx, tx, settings, prMeta.authorID, block, rules, payload.New)
+	} else {
+		picked, err = s.pickWithFallback(ctx, tx, settings, prMeta.authorID, block, rules, 1)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	candidates := picked.reviewers
 	if len(candidates) == 0 {
 		return nil, "", ErrNoCandidate
 	}
@@ -357,10 +769,14 @@ func (s *Store) reassignOnce(ctx context.Context, payload ReassignPayload) (*Pul
 	if err := s.replaceReviewer(ctx, tx, payload.PRID, payload.Old, replacement); err != nil {
 		return nil, "", err
 	}
-	updated, err := s.fetchPRTx(ctx, tx, payload.PRID)
+	updated, err := s.fetchPR(ctx, tx, payload.PRID)
 	if err != nil {
 		return nil, "", err
 	}
+	updated.FallbackReviewers = picked.fromFallback
+	if payload.Explain {
+		updated.Explanation = picked.explanation
+	}
 	if err := tx.Commit(); err != nil {
 		return nil, "", err
 	}
@@ -373,28 +789,26 @@ type prMetaInfo struct {
 }
 
 func (s *Store) loadPRMeta(ctx context.Context, tx *sql.Tx, prID string) (*prMetaInfo, error) {
-	var meta prMetaInfo
-	var status string
-	if err := tx.QueryRowContext(ctx, `SELECT author_id, status FROM pull_requests WHERE pr_id=$1 FOR UPDATE`, prID).
-		Scan(&meta.authorID, &status); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrPRNotFound
-		}
+	authorID, status, err := lockPR(ctx, tx, prID)
+	if err != nil {
 		return nil, err
 	}
-	if status == StatusMerged {
+	switch status {
+	case StatusOpen:
+	case StatusMerged:
 		return nil, ErrPRMerged
+	default:
+		return nil, fmt.Errorf("%w: reviewers of a %s PR cannot change", ErrInvalidStatus, status)
 	}
 	reviewers, err := s.listReviewersTx(ctx, tx, prID)
 	if err != nil {
 		return nil, err
 	}
-	meta.currentReviewers = reviewers
-	return &meta, nil
+	return &prMetaInfo{authorID: authorID, currentReviewers: reviewers}, nil
 }
 
 func (s *Store) lookupReviewerTeam(ctx context.Context, tx *sql.Tx, userID string) (string, error) {
-	var reviewerTeam string
+	var reviewerTeam sql.NullString
 	if err := tx.QueryRowContext(
 		ctx,
 		`SELECT team_name FROM users WHERE user_id=$1`,
@@ -405,7 +819,7 @@ func (s *Store) lookupReviewerTeam(ctx context.Context, tx *sql.Tx, userID strin
 		}
 		return "", err
 	}
-	return reviewerTeam, nil
+	return reviewerTeam.String, nil
 }
 
 func (s *Store) ensureReviewerAssigned(ctx context.Context, tx *sql.Tx, payload ReassignPayload) error {
@@ -424,6 +838,70 @@ func (s *Store) ensureReviewerAssigned(ctx context.Context, tx *sql.Tx, payload
 	return nil
 }
 
+// pickReplacement checks the replacement chosen by the caller: an active user
+// other than the author and the current reviewers, from the old reviewer's
+// team or one of its fallback teams, holding the policy role when rules ask
+// for one. Absences and capacity limits do not apply to a manual handoff.
+func (s *Store) pickReplacement(
+	ctx context.Context,
+	tx *sql.Tx,
+	settings TeamSettings,
+	authorID string,
+	block map[string]struct{},
+	rules selectionRules,
+	target string,
+) (selection, error) {
+	var team sql.NullString
+	var role string
+	var active bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT team_name, is_active, role FROM users WHERE user_id=$1`, target).
+		Scan(&team, &active, &role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return selection{}, ErrUserNotFound
+		}
+		return selection{}, err
+	}
+	if target == authorID {
+		return selection{}, fmt.Errorf("%w: %q is the PR author", ErrIneligibleReviewer, target)
+	}
+	if _, ok := block[target]; ok {
+		return selection{}, ErrAlreadyAssigned
+	}
+	if !active {
+		return selection{}, fmt.Errorf("%w: %q is inactive", ErrIneligibleReviewer, target)
+	}
+	if rules.roleCount > 0 && !roleAtLeast(role, rules.role) {
+		return selection{}, fmt.Errorf("%w: %q does not hold the required role %s", ErrIneligibleReviewer, target, rules.role)
+	}
+	result := selection{reviewers: []string{target}}
+	fromFallback, err := checkReviewerTeam(ctx, tx, settings.TeamName, team.String)
+	if err != nil {
+		return selection{}, err
+	}
+	if fromFallback {
+		result.fromFallback = []string{target}
+	}
+	return result, nil
+}
+
+// checkReviewerTeam makes sure a reviewer picked by hand from team may review
+// PRs of authorTeam and reports whether team is one of its fallback teams.
+func checkReviewerTeam(ctx context.Context, q querier, authorTeam, team string) (bool, error) {
+	if team == authorTeam {
+		return false, nil
+	}
+	fallbackTeams, err := loadFallbackTeams(ctx, q, authorTeam)
+	if err != nil {
+		return false, err
+	}
+	if !slices.Contains(fallbackTeams, team) {
+		return false, fmt.Errorf("%w: team %q is neither %q nor one of its fallback teams",
+			ErrIneligibleReviewer, team, authorTeam)
+	}
+	return true, nil
+}
+
 func (s *Store) buildBlocklist(
 	payload ReassignPayload,
 	currentReviewers []string,
@@ -439,57 +917,10 @@ func (s *Store) buildBlocklist(
 }
 
 func (s *Store) replaceReviewer(ctx context.Context, tx *sql.Tx, prID, oldID, newID string) error {
-	if _, err := tx.ExecContext(
-		ctx,
-		`DELETE FROM assigned_reviewers WHERE pr_id=$1 AND user_id=$2`,
-		prID,
-		oldID,
-	); err != nil {
-		return err
-	}
-	if _, err := tx.ExecContext(
-		ctx,
-		`INSERT INTO assigned_reviewers(pr_id, user_id) VALUES ($1,$2)`,
-		prID,
-		newID,
-	); err != nil {
+	if err := s.unassignReviewer(ctx, tx, prID, oldID, ReasonReassigned, ActorSystem); err != nil {
 		return err
 	}
-	return nil
-}
-
-func (s *Store) UserReviews(ctx context.Context, userID string) ([]PullRequestShort, error) {
-	var exists bool
-	if err := s.db.QueryRowContext(ctx,
-		`SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)`, userID).Scan(&exists); err != nil {
-		return nil, err
-	}
-	if !exists {
-		return nil, ErrUserNotFound
-	}
-	rows, err := s.db.QueryContext(ctx, `
-SELECT pr.pr_id, pr.pr_name, pr.author_id, pr.status
-FROM pull_requests pr
-JOIN assigned_reviewers ar ON ar.pr_id = pr.pr_id
-WHERE ar.user_id = $1
-ORDER BY pr.pr_id
-`, userID)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = rows.Close() }()
-	var prs []PullRequestShort
-	for rows.Next() {
-		var pr PullRequestShort
-		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status); err != nil {
-			return nil, err
-		}
-		prs = append(prs, pr)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return prs, nil
+	return s.assignReviewer(ctx, tx, prID, newID, ReasonReassigned, ActorSystem)
 }
 
 func (s *Store) Stats(ctx context.Context) (*Stats, error) {
@@ -529,6 +960,20 @@ GROUP BY u.user_id
 	).Scan(&stats.MergedPRs); err != nil {
 		return nil, err
 	}
+	if err := s.db.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM pull_requests WHERE status=$1`,
+		StatusDraft,
+	).Scan(&stats.DraftPRs); err != nil {
+		return nil, err
+	}
+	if err := s.db.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM pull_requests WHERE status=$1`,
+		StatusClosed,
+	).Scan(&stats.ClosedPRs); err != nil {
+		return nil, err
+	}
 	return stats, nil
 }
 
@@ -568,7 +1013,7 @@ func (s *Store) massDeactivateOnce(ctx context.Context, teamName string) error {
 	if err != nil {
 		return err
 	}
-	if err := s.reassignAfterDeactivation(ctx, tx, teamName, assignments); err != nil {
+	if _, err := s.reassignAfterDeactivation(ctx, tx, teamName, assignments, ReasonTeamDeactivated); err != nil {
 		return err
 	}
 	return tx.Commit()
@@ -616,6 +1061,29 @@ FOR UPDATE
 	if err != nil {
 		return nil, err
 	}
+	return scanAssignments(rows)
+}
+
+func (s *Store) fetchUserAssignments(
+	ctx context.Context,
+	tx *sql.Tx,
+	userID string,
+) ([]assignment, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT pr.pr_id, pr.author_id, ar.user_id
+FROM pull_requests pr
+JOIN assigned_reviewers ar ON ar.pr_id = pr.pr_id
+WHERE pr.status=$1 AND ar.user_id=$2
+ORDER BY pr.pr_id
+FOR UPDATE
+`, StatusOpen, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanAssignments(rows)
+}
+
+func scanAssignments(rows *sql.Rows) ([]assignment, error) {
 	defer rows.Close()
 
 	var list []assignment
@@ -637,11 +1105,13 @@ func (s *Store) reassignAfterDeactivation(
 	tx *sql.Tx,
 	teamName string,
 	assignments []assignment,
-) error {
+	reason string,
+) (*ReassignmentSummary, error) {
+	summary := &ReassignmentSummary{Reassigned: []Reassignment{}, Unfilled: []string{}}
 	for _, a := range assignments {
 		currentReviewers, err := s.listReviewersTx(ctx, tx, a.prID)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		block := make(map[string]struct{}, len(currentReviewers)+1)
 		for _, r := range currentReviewers {
@@ -649,82 +1119,86 @@ func (s *Store) reassignAfterDeactivation(
 		}
 		block[a.authorID] = struct{}{}
 
-		candidates, err := s.pickCandidates(ctx, tx, teamName, "", block, 1)
+		candidates, err := s.pickDeactivationReplacement(ctx, tx, teamName, a, block)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if err := s.unassignReviewer(ctx, tx, a.prID, a.reviewer, reason, ActorSystem); err != nil {
+			return nil, err
 		}
 		if len(candidates) == 0 {
-			if _, err := tx.ExecContext(
-				ctx,
-				`DELETE FROM assigned_reviewers WHERE pr_id=$1 AND user_id=$2`,
-				a.prID,
-				a.reviewer,
-			); err != nil {
-				return err
-			}
+			summary.Unfilled = append(summary.Unfilled, a.prID)
 			continue
 		}
-		if _, err := tx.ExecContext(
-			ctx,
-			`DELETE FROM assigned_reviewers WHERE pr_id=$1 AND user_id=$2`,
-			a.prID,
-			a.reviewer,
-		); err != nil {
-			return err
-		}
-		if _, err := tx.ExecContext(
-			ctx,
-			`INSERT INTO assigned_reviewers(pr_id, user_id) VALUES ($1,$2)`,
-			a.prID,
-			candidates[0],
-		); err != nil {
-			return err
+		if err := s.assignReviewer(ctx, tx, a.prID, candidates[0], reason, ActorSystem); err != nil {
+			return nil, err
 		}
+		summary.Reassigned = append(summary.Reassigned, Reassignment{
+			PRID:      a.prID,
+			OldUserID: a.reviewer,
+			NewUserID: candidates[0],
+		})
 	}
-	return nil
+	return summary, nil
 }
 
-func (s *Store) fetchPRTx(ctx context.Context, tx *sql.Tx, prID string) (*PullRequest, error) {
-	row := tx.QueryRowContext(ctx, `
+// pickDeactivationReplacement picks a replacement for a.reviewer by the rules
+// of /pullRequest/reassign: the role policy of the author's team applies and
+// fallback teams fill in when teamName has nobody left. A policy nobody can
+// satisfy leaves the PR short rather than handing a required role to an
+// ordinary member; a user without a team has no teammates to hand over to.
+func (s *Store) pickDeactivationReplacement(
+	ctx context.Context,
+	tx *sql.Tx,
+	teamName string,
+	a assignment,
+	block map[string]struct{},
+) ([]string, error) {
+	if teamName == "" {
+		return nil, nil
+	}
+	rules, err := reassignPolicy(ctx, tx, a.prID, a.reviewer)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := loadTeamSettings(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	picked, err := s.pickWithFallback(ctx, tx, settings, a.authorID, block, rules, 1)
+	if errors.Is(err, ErrPolicyUnsatisfiable) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return picked.reviewers, nil
+}
+
+func (s *Store) fetchPR(ctx context.Context, q querier, prID string) (*PullRequest, error) {
+	row := q.QueryRowContext(ctx, `
 SELECT pr_id, pr_name, author_id, status, created_at, merged_at
 FROM pull_requests
 WHERE pr_id=$1
 `, prID)
 	var pr PullRequest
-	if err := row.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt); err != nil {
+	var authorID sql.NullString
+	if err := row.Scan(&pr.ID, &pr.Name, &authorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrPRNotFound
 		}
 		return nil, err
 	}
-	reviewers, err := s.listReviewersTx(ctx, tx, prID)
+	pr.AuthorID = authorID.String
+	reviewers, reviews, err := listReviews(ctx, q, prID)
 	if err != nil {
 		return nil, err
 	}
 	pr.AssignedReviewers = reviewers
+	pr.Reviews = reviews
 	return &pr, nil
 }
 
-func (s *Store) listReviewers(ctx context.Context, prID string) ([]string, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT user_id FROM assigned_reviewers WHERE pr_id=$1 ORDER BY user_id`, prID)
-	if err != nil {
-		return nil, err
-	}
-	defer func() { _ = rows.Close() }()
-	var out []string
-	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err != nil {
-			return nil, err
-		}
-		out = append(out, id)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return out, nil
-}
-
 func (s *Store) listReviewersTx(ctx context.Context, tx *sql.Tx, prID string) ([]string, error) {
 	rows, err := tx.QueryContext(ctx, `SELECT user_id FROM assigned_reviewers WHERE pr_id=$1 ORDER BY user_id`, prID)
 	if err != nil {
@@ -753,35 +1227,151 @@ func (s *Store) pickCandidates(
 	block map[string]struct{},
 	limit int,
 ) ([]string, error) {
+	settings, err := loadTeamSettings(ctx, q, teamName)
+	if err != nil {
+		return nil, err
+	}
+	selected, _, err := s.pickFromTeam(ctx, q, settings, exclude, block, selectionRules{}, limit)
+	return selected, err
+}
+
+func (s *Store) pickFromTeam(
+	ctx context.Context,
+	q querier,
+	settings TeamSettings,
+	exclude string,
+	block map[string]struct{},
+	rules selectionRules,
+	limit int,
+) ([]string, SelectionExplanation, error) {
+	members, err := loadTeamMembers(ctx, q, settings.TeamName)
+	if err != nil {
+		return nil, SelectionExplanation{}, err
+	}
+	candidates, excluded := eligibleCandidates(members, exclude, block, settings.DefaultMaxOpenReviews)
+	candidates, excluded = dropExcluded(candidates, excluded, rules.excluded)
+	strategy := s.strategyFor(settings.ReviewerStrategy)
+	explanation := SelectionExplanation{
+		TeamName: settings.TeamName,
+		Strategy: strategy,
+		Pool:     candidateIDs(candidates),
+		Excluded: excluded,
+	}
+
+	choose := s.selectors[strategy].Select
+	if settings.PairingWindow > 0 && len(candidates) > 0 {
+		pairings, err := loadRecentPairings(ctx, q, exclude, settings.PairingWindow)
+		if err != nil {
+			return nil, SelectionExplanation{}, err
+		}
+		for i := range candidates {
+			candidates[i].RecentPairings = pairings[candidates[i].UserID]
+			if n := candidates[i].RecentPairings; n > 0 {
+				if explanation.RecentPairings == nil {
+					explanation.RecentPairings = make(map[string]int)
+				}
+				explanation.RecentPairings[candidates[i].UserID] = n
+			}
+		}
+		sel := s.selectors[strategy]
+		choose = func(pool []Candidate, n int) []string { return selectWithRotation(sel, pool, n) }
+	}
+
+	var selected []string
+	pool := candidates
+	// Slots the role policy could not fill here stay open for fallback teams.
+	reserved := 0
+	if rules.roleCount > 0 {
+		selected = choose(roleHolders(candidates, rules.role), min(rules.roleCount, limit))
+		explanation.PolicyReviewers = append([]string{}, selected...)
+		pool = withoutCandidates(candidates, selected)
+		reserved = min(rules.roleCount, limit) - len(selected)
+	}
+	if len(rules.requiredTags) > 0 && len(candidates) > 0 {
+		skills, err := loadTeamSkills(ctx, q, settings.TeamName)
+		if err != nil {
+			return nil, SelectionExplanation{}, err
+		}
+		covered := tagsCoveredBy(selected, skills, rules.requiredTags)
+		more, newlyCovered := coverTags(choose, pool, skills,
+			uncoveredTags(rules.requiredTags, covered), limit-reserved-len(selected))
+		for tag, id := range newlyCovered {
+			covered[tag] = id
+		}
+		explanation.CoveredTags = covered
+		selected = append(selected, more...)
+		pool = withoutCandidates(pool, more)
+	}
+	selected = append(selected, choose(pool, limit-reserved-len(selected))...)
+	explanation.Selected = append([]string{}, selected...)
+	return selected, explanation, nil
+}
+
+func withoutCandidates(candidates []Candidate, ids []string) []Candidate {
+	out := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if !slices.Contains(ids, c.UserID) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func effectiveCapacity(personal, teamDefault int) int {
+	if personal > 0 {
+		return personal
+	}
+	return teamDefault
+}
+
+// strategyFor resolves the team strategy, falling back to the store default.
+func (s *Store) strategyFor(strategy string) string {
+	if _, ok := s.selectors[strategy]; ok {
+		return strategy
+	}
+	return s.defaultStrategy
+}
+
+// loadTeamMembers returns every member of the team together with the flags
+// eligibility is decided on; filtering happens in eligibleCandidates so the
+// reasons can be reported.
+func loadTeamMembers(ctx context.Context, q querier, teamName string) ([]teamMember, error) {
 	rows, err := q.QueryContext(ctx, `
-SELECT user_id FROM users
-WHERE team_name=$1 AND is_active=true AND user_id<>$2
-`, teamName, exclude)
+SELECT u.user_id, u.is_active, u.review_weight, COALESCE(u.max_open_reviews, 0),
+       EXISTS (
+           SELECT 1 FROM user_absences ua
+           WHERE ua.user_id = u.user_id AND ua.starts_at <= now() AND ua.ends_at > now()
+       ),
+       MAX(ar.assigned_at), COUNT(pr.pr_id), u.role
+FROM users u
+LEFT JOIN assigned_reviewers ar ON ar.user_id = u.user_id
+LEFT JOIN pull_requests pr ON pr.pr_id = ar.pr_id AND pr.status = $2
+WHERE u.team_name=$1
+GROUP BY u.user_id, u.is_active, u.review_weight, u.max_open_reviews, u.role
+ORDER BY u.user_id
+`, teamName, StatusOpen)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
-	var ids []string
+	var members []teamMember
 	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err != nil {
+		var m teamMember
+		var lastAssigned sql.NullTime
+		if err := rows.Scan(
+			&m.UserID, &m.active, &m.Weight, &m.MaxOpenReviews, &m.absent, &lastAssigned, &m.OpenReviews, &m.Role,
+		); err != nil {
 			return nil, err
 		}
-		if block != nil {
-			if _, ok := block[id]; ok {
-				continue
-			}
+		if lastAssigned.Valid {
+			m.LastAssignedAt = &lastAssigned.Time
 		}
-		ids = append(ids, id)
+		members = append(members, m)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	s.rnd.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
-	if len(ids) > limit {
-		ids = ids[:limit]
-	}
-	return ids, nil
+	return members, nil
 }
 
 type querier interface {
@@ -790,12 +1380,21 @@ type querier interface {
 }
 
 func buildTeam(ctx context.Context, q querier, teamName string) (TeamPayload, error) {
+	settings, err := loadTeamSettings(ctx, q, teamName)
+	if err != nil {
+		return TeamPayload{}, err
+	}
 	rows, err := q.QueryContext(ctx, `
-SELECT u.user_id, u.username, u.is_active
+SELECT u.user_id, u.username, u.is_active, u.review_weight, COALESCE(u.max_open_reviews, 0),
+       (SELECT COUNT(*)
+        FROM assigned_reviewers ar
+        JOIN pull_requests pr ON pr.pr_id = ar.pr_id
+        WHERE ar.user_id = u.user_id AND pr.status = $2),
+       u.role
 FROM users u
 WHERE u.team_name=$1
 ORDER BY u.user_id
-`, teamName)
+`, teamName, StatusOpen)
 	if err != nil {
 		return TeamPayload{}, err
 	}
@@ -803,28 +1402,36 @@ ORDER BY u.user_id
 	members := make([]TeamUpserted, 0)
 	for rows.Next() {
 		var m TeamUpserted
-		if err := rows.Scan(&m.UserID, &m.Username, &m.IsActive); err != nil {
+		err := rows.Scan(&m.UserID, &m.Username, &m.IsActive, &m.ReviewWeight, &m.MaxOpenReviews, &m.OpenReviews, &m.Role)
+		if err != nil {
 			return TeamPayload{}, err
 		}
+		m.Capacity = effectiveCapacity(m.MaxOpenReviews, settings.DefaultMaxOpenReviews)
 		members = append(members, m)
 	}
 	if rows.Err() != nil {
 		return TeamPayload{}, rows.Err()
 	}
-	if len(members) == 0 {
-		var exists bool
-		if err := q.QueryRowContext(
-			ctx,
-			`SELECT EXISTS(SELECT 1 FROM teams WHERE name=$1)`,
-			teamName,
-		).Scan(&exists); err != nil {
-			return TeamPayload{}, err
-		}
-		if !exists {
-			return TeamPayload{}, ErrTeamNotFound
-		}
+	skills, err := loadTeamSkills(ctx, q, teamName)
+	if err != nil {
+		return TeamPayload{}, err
+	}
+	for i := range members {
+		members[i].Skills = skills[members[i].UserID]
+	}
+	fallbackTeams, err := loadFallbackTeams(ctx, q, teamName)
+	if err != nil {
+		return TeamPayload{}, err
 	}
-	return TeamPayload{TeamName: teamName, Members: members}, nil
+	return TeamPayload{
+		TeamName:              teamName,
+		ReviewerStrategy:      settings.ReviewerStrategy,
+		MinReviewers:          &settings.MinReviewers,
+		MaxReviewers:          &settings.MaxReviewers,
+		DefaultMaxOpenReviews: settings.DefaultMaxOpenReviews,
+		FallbackTeams:         fallbackTeams,
+		Members:               members,
+	}, nil
 }
 
 func isUniqueViolation(err error) bool {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
@@ -27,21 +28,107 @@ func newMockStore(t *testing.T) (*Store, sqlmock.Sqlmock, func()) {
 	return store, mock, func() { db.Close() }
 }
 
+const teamSettingsPattern = `SELECT reviewer_strategy, min_reviewers, max_reviewers,\s+COALESCE\(default_max_open_reviews, 0\),\s+COALESCE\(pairing_window, 0\),\s+COALESCE\(required_role, ''\), COALESCE\(required_role_count, 0\),\s+COALESCE\(required_approvals, 0\)\s+FROM teams`
+
+func teamSettingsRows(strategy any, minReviewers, maxReviewers int) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"reviewer_strategy", "min_reviewers", "max_reviewers", "default_max_open_reviews", "pairing_window", "required_role", "required_role_count", "required_approvals"}).
+		AddRow(strategy, minReviewers, maxReviewers, 0, 0, "", 0, 0)
+}
+
+// expectTeamSettings registers a settings lookup returning the default bounds.
+func expectTeamSettings(mock sqlmock.Sqlmock, team string) {
+	mock.ExpectQuery(teamSettingsPattern).
+		WithArgs(team).
+		WillReturnRows(teamSettingsRows(nil, DefaultMinReviewers, DefaultMaxReviewers))
+}
+
+// expectCandidates registers the team settings lookup and candidate query issued by pickCandidates.
+func expectCandidates(mock sqlmock.Sqlmock, team string, ids ...string) {
+	expectTeamSettings(mock, team)
+	expectCandidateRows(mock, team, ids...)
+}
+
+// expectDeactivationPick registers the lookups of a replacement for a
+// deactivated reviewer on a PR without a role policy.
+func expectDeactivationPick(mock sqlmock.Sqlmock, prID, team string, ids ...string) {
+	expectRolePolicy(mock, prID, "", 0)
+	expectCandidates(mock, team, ids...)
+}
+
+func expectTeamSkills(mock sqlmock.Sqlmock, team string) {
+	mock.ExpectQuery(`SELECT us.user_id, us.skill`).WithArgs(team).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "skill"}))
+}
+
+// expectRolePolicy registers the role policy lookup of a reassign.
+func expectRolePolicy(mock sqlmock.Sqlmock, prID, role string, count int) {
+	mock.ExpectQuery(`SELECT COALESCE\(t.required_role, ''\), COALESCE\(t.required_role_count, 0\)`).
+		WithArgs(prID).
+		WillReturnRows(sqlmock.NewRows([]string{"required_role", "required_role_count"}).AddRow(role, count))
+}
+
+func expectFallbackTeams(mock sqlmock.Sqlmock, team string, fallbacks ...string) {
+	rows := sqlmock.NewRows([]string{"fallback_team"})
+	for _, fb := range fallbacks {
+		rows.AddRow(fb)
+	}
+	mock.ExpectQuery(`SELECT fallback_team\s+FROM team_fallbacks`).WithArgs(team).WillReturnRows(rows)
+}
+
+func expectEvent(mock sqlmock.Sqlmock, prID, userID, action, reason string) {
+	mock.ExpectExec(`INSERT INTO assignment_events`).
+		WithArgs(prID, userID, action, reason, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+var memberColumns = []string{
+	"user_id", "is_active", "review_weight", "max_open_reviews", "absent", "last_assigned_at", "open_reviews", "role",
+}
+
+// memberRows builds the result of loadTeamMembers; every id is an active, present member without load.
+func memberRows(ids ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows(memberColumns)
+	for _, id := range ids {
+		rows.AddRow(id, true, 1, 0, false, nil, 0, RoleMember)
+	}
+	return rows
+}
+
+const membersPattern = `SELECT u.user_id, u.is_active, u.review_weight`
+
+const reviewsPattern = `SELECT user_id, verdict, verdict_at\s+FROM assigned_reviewers`
+
+// reviewRows builds the result of listReviews; none of the reviewers has submitted a verdict.
+func reviewRows(ids ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"user_id", "verdict", "verdict_at"})
+	for _, id := range ids {
+		rows.AddRow(id, nil, nil)
+	}
+	return rows
+}
+
+func expectCandidateRows(mock sqlmock.Sqlmock, team string, ids ...string) {
+	mock.ExpectQuery(membersPattern).WithArgs(team, StatusOpen).WillReturnRows(memberRows(ids...))
+}
+
 func TestAddTeamSuccess(t *testing.T) {
 	store, mock, cleanup := newMockStore(t)
 	defer cleanup()
 
 	mock.ExpectBegin()
-	mock.ExpectExec(`INSERT INTO teams`).WithArgs("backend").WillReturnResult(sqlmock.NewResult(0, 1))
-	mock.ExpectExec(`INSERT INTO users`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "backend").
+	mock.ExpectExec(`INSERT INTO teams`).WithArgs("backend", "", DefaultMinReviewers, DefaultMaxReviewers, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO users`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "backend", 1, 0, RoleMember).
 		WillReturnResult(sqlmock.NewResult(0, 1))
-	mock.ExpectExec(`INSERT INTO users`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "backend").
+	mock.ExpectExec(`INSERT INTO users`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "backend", 1, 0, RoleMember).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectTeamSettings(mock, "backend")
 	mock.ExpectQuery(`SELECT u.user_id, u.username, u.is_active`).
-		WithArgs("backend").
-		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "is_active"}).
-			AddRow("u1", "Alice", true).
-			AddRow("u2", "Bob", false))
+		WithArgs("backend", StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "is_active", "review_weight", "max_open_reviews", "open_reviews", "role"}).
+			AddRow("u1", "Alice", true, 1, 0, 0, RoleMember).
+			AddRow("u2", "Bob", false, 1, 0, 0, RoleMember))
+	expectTeamSkills(mock, "backend")
+	expectFallbackTeams(mock, "backend")
 	mock.ExpectCommit()
 
 	payload := TeamPayload{
@@ -64,13 +151,16 @@ func TestAddTeamDuplicateSkipsExtra(t *testing.T) {
 	defer cleanup()
 
 	mock.ExpectBegin()
-	mock.ExpectExec(`INSERT INTO teams`).WithArgs("backend").WillReturnResult(sqlmock.NewResult(0, 1))
-	mock.ExpectExec(`INSERT INTO users`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "backend").
+	mock.ExpectExec(`INSERT INTO teams`).WithArgs("backend", "", DefaultMinReviewers, DefaultMaxReviewers, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO users`).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "backend", 1, 0, RoleMember).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectTeamSettings(mock, "backend")
 	mock.ExpectQuery(`SELECT u.user_id, u.username, u.is_active`).
-		WithArgs("backend").
-		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "is_active"}).
-			AddRow("u1", "Alice", true))
+		WithArgs("backend", StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "is_active", "review_weight", "max_open_reviews", "open_reviews", "role"}).
+			AddRow("u1", "Alice", true, 1, 0, 0, RoleMember))
+	expectTeamSkills(mock, "backend")
+	expectFallbackTeams(mock, "backend")
 	mock.ExpectCommit()
 
 	payload := TeamPayload{
@@ -90,7 +180,7 @@ func TestAddTeamExists(t *testing.T) {
 	defer cleanup()
 
 	mock.ExpectBegin()
-	mock.ExpectExec(`INSERT INTO teams`).WithArgs("backend").
+	mock.ExpectExec(`INSERT INTO teams`).WithArgs("backend", "", DefaultMinReviewers, DefaultMaxReviewers, 0).
 		WillReturnError(errors.New("duplicate key value"))
 	mock.ExpectRollback()
 
@@ -112,6 +202,10 @@ func TestStats(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
 	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM pull_requests WHERE status=\$1`).WithArgs(StatusMerged).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM pull_requests WHERE status=\$1`).WithArgs(StatusDraft).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM pull_requests WHERE status=\$1`).WithArgs(StatusClosed).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
 
 	stats, err := store.Stats(context.Background())
 	if err != nil {
@@ -120,7 +214,7 @@ func TestStats(t *testing.T) {
 	if stats.AssignmentsPerUser["u1"] != 2 || stats.AssignmentsPerUser["u2"] != 0 {
 		t.Fatalf("unexpected assignments: %+v", stats.AssignmentsPerUser)
 	}
-	if stats.OpenPRs != 3 || stats.MergedPRs != 5 {
+	if stats.OpenPRs != 3 || stats.MergedPRs != 5 || stats.DraftPRs != 1 || stats.ClosedPRs != 2 {
 		t.Fatalf("unexpected counts: %+v", stats)
 	}
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -132,12 +226,7 @@ func TestPickCandidatesHonorsExcludeAndBlock(t *testing.T) {
 	store, mock, cleanup := newMockStore(t)
 	defer cleanup()
 
-	rows := sqlmock.NewRows([]string{"user_id"}).
-		AddRow("u1").
-		AddRow("u2").
-		AddRow("u3")
-	mock.ExpectQuery(`SELECT user_id FROM users`).WithArgs("backend", "author").
-		WillReturnRows(rows)
+	expectCandidates(mock, "backend", "author", "u1", "u2", "u3")
 
 	const (
 		teamBackend = "backend"
@@ -173,17 +262,18 @@ func TestCreatePRAssignsReviewers(t *testing.T) {
 	mock.ExpectQuery(`SELECT user_id, team_name FROM users WHERE user_id=`).
 		WithArgs(authorID).
 		WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_name"}).AddRow(authorID, teamBackend))
-	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).
-		WithArgs(teamBackend).
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
-	mock.ExpectQuery(`SELECT user_id FROM users`).
-		WithArgs(teamBackend, authorID).
-		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u1").AddRow("u2"))
-	mock.ExpectExec(`INSERT INTO pull_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectCandidates(mock, teamBackend, "u1", "u2")
+	// The PR keeps the team its author is in at creation.
+	mock.ExpectExec(`INSERT INTO pull_requests\(pr_id, pr_name, author_id, team_name, status, created_at\)\s+`+
+		`VALUES \(\$1,\$2,\$3,\(SELECT team_name FROM users WHERE user_id=\$3\),\$4,\$5\)`).
+		WithArgs("pr1", sqlmock.AnyArg(), authorID, StatusOpen, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "u1").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "u1", EventAssigned, ReasonPRCreated)
 	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "u2").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "u2", EventAssigned, ReasonPRCreated)
 	mock.ExpectCommit()
 
 	pr, err := store.CreatePR(context.Background(), CreatePRPayload{
@@ -206,12 +296,17 @@ func TestMergePRIdempotent(t *testing.T) {
 	store, mock, cleanup := newMockStore(t)
 	defer cleanup()
 
-	mock.ExpectQuery(`UPDATE pull_requests`).
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT author_id, status FROM pull_requests WHERE pr_id=\$1 FOR UPDATE`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "status"}).AddRow("author", StatusMerged))
+	mock.ExpectExec(`UPDATE pull_requests`).
 		WithArgs("pr1", StatusMerged).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT pr_id, pr_name, author_id, status, created_at, merged_at FROM pull_requests`).WithArgs("pr1").
 		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "pr_name", "author_id", "status", "created_at", "merged_at"}).
 			AddRow("pr1", "feature", "author", StatusMerged, time.Now(), time.Now()))
-	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers`).WithArgs("pr1").
-		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u1").AddRow("u2"))
+	mock.ExpectQuery(reviewsPattern).WithArgs("pr1").WillReturnRows(reviewRows("u1", "u2"))
+	mock.ExpectCommit()
 
 	pr, err := store.MergePR(context.Background(), "pr1")
 	if err != nil {
@@ -238,18 +333,19 @@ func TestReassignHappyPath(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow("backend"))
 	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM assigned_reviewers`).WithArgs("pr1", "old").
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
-	mock.ExpectQuery(`SELECT user_id FROM users`).WithArgs("backend", "").
-		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("cand"))
+	expectRolePolicy(mock, "pr1", "", 0)
+	expectCandidates(mock, "backend", "cand")
 	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "old").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "old", EventUnassigned, ReasonReassigned)
 	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "cand").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "cand", EventAssigned, ReasonReassigned)
 	mock.ExpectQuery(`SELECT pr_id, pr_name, author_id, status, created_at, merged_at FROM pull_requests`).
 		WithArgs("pr1").
 		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "pr_name", "author_id", "status", "created_at", "merged_at"}).
 			AddRow("pr1", "feature", "author", StatusOpen, time.Now(), time.Now()))
-	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers`).WithArgs("pr1").
-		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("cand").AddRow("other"))
+	mock.ExpectQuery(reviewsPattern).WithArgs("pr1").WillReturnRows(reviewRows("cand", "other"))
 	mock.ExpectCommit()
 
 	pr, replaced, err := store.Reassign(context.Background(), ReassignPayload{PRID: "pr1", Old: "old"})
@@ -282,10 +378,11 @@ func TestMassDeactivateNoCandidates(t *testing.T) {
 	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers WHERE pr_id=`).
 		WithArgs("pr1").
 		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("rev1"))
-	mock.ExpectQuery(`SELECT user_id FROM users`).WithArgs("backend", "").
-		WillReturnRows(sqlmock.NewRows([]string{"user_id"})) // no candidates
+	expectDeactivationPick(mock, "pr1", "backend") // no candidates
+	expectFallbackTeams(mock, "backend")
 	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "rev1").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "rev1", EventUnassigned, ReasonTeamDeactivated)
 	mock.ExpectCommit()
 
 	if err := store.MassDeactivate(context.Background(), "backend"); err != nil {
@@ -314,12 +411,13 @@ func TestMassDeactivateWithReplacement(t *testing.T) {
 	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers WHERE pr_id=`).
 		WithArgs("pr1").
 		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("rev1"))
-	mock.ExpectQuery(`SELECT user_id FROM users`).WithArgs("backend", "").
-		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("cand1"))
+	expectDeactivationPick(mock, "pr1", "backend", "cand1")
 	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "rev1").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "rev1", EventUnassigned, ReasonTeamDeactivated)
 	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "cand1").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "cand1", EventAssigned, ReasonTeamDeactivated)
 	mock.ExpectCommit()
 
 	if err := store.MassDeactivate(context.Background(), "backend"); err != nil {
@@ -376,9 +474,9 @@ func TestCreatePRTeamMissing(t *testing.T) {
 	mock.ExpectQuery(`SELECT user_id, team_name FROM users WHERE user_id=`).
 		WithArgs("author").
 		WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_name"}).AddRow("author", "backend"))
-	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).
+	mock.ExpectQuery(teamSettingsPattern).
 		WithArgs("backend").
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		WillReturnRows(sqlmock.NewRows([]string{"reviewer_strategy", "min_reviewers", "max_reviewers", "default_max_open_reviews", "pairing_window", "required_role", "required_role_count", "required_approvals"}))
 	mock.ExpectRollback()
 
 	_, err := store.CreatePR(context.Background(), CreatePRPayload{ID: "pr1", Author: "author"})
@@ -436,8 +534,9 @@ func TestReassignNoCandidate(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow("backend"))
 	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM assigned_reviewers`).WithArgs("pr1", "old").
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
-	mock.ExpectQuery(`SELECT user_id FROM users`).WithArgs("backend", "").
-		WillReturnRows(sqlmock.NewRows([]string{"user_id"})) // empty
+	expectRolePolicy(mock, "pr1", "", 0)
+	expectCandidates(mock, "backend") // empty
+	expectFallbackTeams(mock, "backend")
 	mock.ExpectRollback()
 
 	_, _, err := store.Reassign(context.Background(), ReassignPayload{PRID: "pr1", Old: "old"})
@@ -446,6 +545,95 @@ func TestReassignNoCandidate(t *testing.T) {
 	}
 }
 
+// expectReassignLookups registers the lookups of a reassign of "old" on pr1
+// before a replacement is chosen.
+func expectReassignLookups(mock sqlmock.Sqlmock, reviewers ...string) {
+	rows := sqlmock.NewRows([]string{"user_id"})
+	for _, id := range reviewers {
+		rows.AddRow(id)
+	}
+	mock.ExpectQuery(`SELECT author_id, status FROM pull_requests`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "status"}).AddRow("author", StatusOpen))
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers`).WithArgs("pr1").WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT team_name FROM users`).WithArgs("old").
+		WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow("backend"))
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM assigned_reviewers`).WithArgs("pr1", "old").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	expectTeamSettings(mock, "backend")
+	expectRolePolicy(mock, "pr1", "", 0)
+}
+
+func expectReplacementUser(mock sqlmock.Sqlmock, userID, team string, active bool) {
+	mock.ExpectQuery(`SELECT team_name, is_active, role FROM users WHERE user_id=`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"team_name", "is_active", "role"}).AddRow(team, active, RoleMember))
+}
+
+func TestReassignToChosenReviewer(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectReassignLookups(mock, "old", "other")
+	expectReplacementUser(mock, "helper", "platform", true)
+	expectFallbackTeams(mock, "backend", "platform")
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "old").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "old", EventUnassigned, ReasonReassigned)
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "helper").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "helper", EventAssigned, ReasonReassigned)
+	mock.ExpectQuery(`SELECT pr_id, pr_name, author_id, status, created_at, merged_at FROM pull_requests`).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "pr_name", "author_id", "status", "created_at", "merged_at"}).
+			AddRow("pr1", "feature", "author", StatusOpen, time.Now(), nil))
+	mock.ExpectQuery(reviewsPattern).WithArgs("pr1").WillReturnRows(reviewRows("helper", "other"))
+	mock.ExpectCommit()
+
+	pr, replaced, err := store.Reassign(context.Background(), ReassignPayload{PRID: "pr1", Old: "old", New: "helper"})
+	if err != nil {
+		t.Fatalf("Reassign error: %v", err)
+	}
+	if replaced != "helper" || !reflect.DeepEqual(pr.FallbackReviewers, []string{"helper"}) {
+		t.Fatalf("unexpected result: %+v, replaced=%s", pr, replaced)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestReassignToChosenReviewerValidation(t *testing.T) {
+	cases := []struct {
+		name    string
+		target  string
+		team    string
+		active  bool
+		wantErr error
+	}{
+		{"author", "author", "backend", true, ErrIneligibleReviewer},
+		{"already assigned", "other", "backend", true, ErrAlreadyAssigned},
+		{"inactive", "idle", "backend", false, ErrIneligibleReviewer},
+		{"foreign team", "stranger", "design", true, ErrIneligibleReviewer},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store, mock, cleanup := newMockStore(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			expectReassignLookups(mock, "old", "other")
+			expectReplacementUser(mock, tc.target, tc.team, tc.active)
+			expectFallbackTeams(mock, "backend", "platform")
+			mock.ExpectRollback()
+
+			_, _, err := store.Reassign(context.Background(), ReassignPayload{PRID: "pr1", Old: "old", New: tc.target})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestUserReviewsUserMissing(t *testing.T) {
 	store, mock, cleanup := newMockStore(t)
 	defer cleanup()
@@ -454,7 +642,7 @@ func TestUserReviewsUserMissing(t *testing.T) {
 		WithArgs("u404").
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
-	_, err := store.UserReviews(context.Background(), "u404")
+	_, err := store.UserReviews(context.Background(), "u404", PRFilter{})
 	if !errors.Is(err, ErrUserNotFound) {
 		t.Fatalf("expected ErrUserNotFound, got %v", err)
 	}
@@ -467,17 +655,18 @@ func TestUserReviewsSuccess(t *testing.T) {
 	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM users WHERE user_id=`).
 		WithArgs("u1").
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
-	mock.ExpectQuery(`SELECT pr.pr_id, pr.pr_name, pr.author_id, pr.status`).
+	mock.ExpectQuery(`WITH page AS \(.*r.user_id = \$1\)\s+ORDER BY pr.pr_id\s+\)`).
 		WithArgs("u1").
-		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "pr_name", "author_id", "status"}).
-			AddRow("pr1", "feature", "u2", StatusOpen).
-			AddRow("pr2", "bugfix", "u3", StatusMerged))
+		WillReturnRows(sqlmock.NewRows(prListColumns).
+			AddRow("pr1", "feature", "u2", StatusOpen, time.Now(), nil, "u1", nil, nil).
+			AddRow("pr2", "bugfix", "u3", StatusMerged, time.Now(), time.Now(), "u1", nil, nil))
 
-	prs, err := store.UserReviews(context.Background(), "u1")
+	page, err := store.UserReviews(context.Background(), "u1", PRFilter{})
 	if err != nil {
 		t.Fatalf("UserReviews error: %v", err)
 	}
-	if len(prs) != 2 || prs[0].ID != "pr1" || prs[1].ID != "pr2" {
+	prs := page.PullRequests
+	if len(prs) != 2 || prs[0].ID != "pr1" || prs[1].ID != "pr2" || page.NextCursor != "" {
 		t.Fatalf("unexpected prs: %+v", prs)
 	}
 }
@@ -491,13 +680,16 @@ func TestSetUserActiveSuccess(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "team_name", "is_active"}).
 			AddRow("u1", "alice", "backend", true))
 
-	u, err := store.SetUserActive(context.Background(), SetActivePayload{UserID: "u1", IsActive: true})
+	u, summary, err := store.SetUserActive(context.Background(), SetActivePayload{UserID: "u1", IsActive: true})
 	if err != nil {
 		t.Fatalf("SetUserActive error: %v", err)
 	}
 	if !u.IsActive || u.TeamName != teamBackend || u.ID != "u1" {
 		t.Fatalf("unexpected user: %+v", u)
 	}
+	if summary != nil {
+		t.Fatalf("activation must not reassign anything, got %+v", summary)
+	}
 }
 
 func TestSetUserActiveNotFound(t *testing.T) {
@@ -508,21 +700,96 @@ func TestSetUserActiveNotFound(t *testing.T) {
 		WithArgs("u404", true).
 		WillReturnError(sql.ErrNoRows)
 
-	_, err := store.SetUserActive(context.Background(), SetActivePayload{UserID: "u404", IsActive: true})
+	_, _, err := store.SetUserActive(context.Background(), SetActivePayload{UserID: "u404", IsActive: true})
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestSetUserInactiveReassignsOpenReviews(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE users`).
+		WithArgs("u1", false).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "team_name", "is_active"}).
+			AddRow("u1", "alice", "backend", false))
+	mock.ExpectQuery(`SELECT pr.pr_id, pr.author_id, ar.user_id`).
+		WithArgs(StatusOpen, "u1").
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "author_id", "user_id"}).
+			AddRow("pr1", "author", "u1").
+			AddRow("pr2", "author", "u1"))
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers WHERE pr_id=`).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u1"))
+	expectDeactivationPick(mock, "pr1", "backend", "u3")
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "u1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "u1", EventUnassigned, ReasonUserDeactivated)
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "u3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "u3", EventAssigned, ReasonUserDeactivated)
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers WHERE pr_id=`).
+		WithArgs("pr2").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u1").AddRow("u3"))
+	expectDeactivationPick(mock, "pr2", "backend", "u3")
+	expectFallbackTeams(mock, "backend")
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr2", "u1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr2", "u1", EventUnassigned, ReasonUserDeactivated)
+	mock.ExpectCommit()
+
+	u, summary, err := store.SetUserActive(context.Background(), SetActivePayload{UserID: "u1", IsActive: false})
+	if err != nil {
+		t.Fatalf("SetUserActive error: %v", err)
+	}
+	if u.IsActive {
+		t.Fatalf("user should be inactive: %+v", u)
+	}
+	want := []Reassignment{{PRID: "pr1", OldUserID: "u1", NewUserID: "u3"}}
+	if !reflect.DeepEqual(summary.Reassigned, want) {
+		t.Fatalf("reassigned = %+v, want %+v", summary.Reassigned, want)
+	}
+	if !reflect.DeepEqual(summary.Unfilled, []string{"pr2"}) {
+		t.Fatalf("unfilled = %+v", summary.Unfilled)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSetUserInactiveNotFoundRollsBack(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE users`).
+		WithArgs("u404", false).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, _, err := store.SetUserActive(context.Background(), SetActivePayload{UserID: "u404", IsActive: false})
 	if !errors.Is(err, ErrUserNotFound) {
 		t.Fatalf("expected ErrUserNotFound, got %v", err)
 	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
 }
 
 func TestGetTeamSuccess(t *testing.T) {
 	store, mock, cleanup := newMockStore(t)
 	defer cleanup()
 
+	expectTeamSettings(mock, "backend")
 	mock.ExpectQuery(`SELECT u.user_id, u.username, u.is_active`).
-		WithArgs("backend").
-		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "is_active"}).
-			AddRow("u1", "alice", true).
-			AddRow("u2", "bob", false))
+		WithArgs("backend", StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "is_active", "review_weight", "max_open_reviews", "open_reviews", "role"}).
+			AddRow("u1", "alice", true, 1, 0, 0, RoleMember).
+			AddRow("u2", "bob", false, 1, 0, 0, RoleMember))
+	expectTeamSkills(mock, "backend")
+	expectFallbackTeams(mock, "backend")
 
 	team, err := store.GetTeam(context.Background(), "backend")
 	if err != nil {
@@ -537,12 +804,9 @@ func TestGetTeamNotFound(t *testing.T) {
 	store, mock, cleanup := newMockStore(t)
 	defer cleanup()
 
-	mock.ExpectQuery(`SELECT u.user_id, u.username, u.is_active`).
+	mock.ExpectQuery(teamSettingsPattern).
 		WithArgs("unknown").
-		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "is_active"}))
-	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).
-		WithArgs("unknown").
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		WillReturnRows(sqlmock.NewRows([]string{"reviewer_strategy", "min_reviewers", "max_reviewers", "default_max_open_reviews", "pairing_window", "required_role", "required_role_count", "required_approvals"}))
 
 	_, err := store.GetTeam(context.Background(), "unknown")
 	if !errors.Is(err, ErrTeamNotFound) {
@@ -554,12 +818,12 @@ func TestGetTeamExistsButEmpty(t *testing.T) {
 	store, mock, cleanup := newMockStore(t)
 	defer cleanup()
 
+	expectTeamSettings(mock, "empty")
 	mock.ExpectQuery(`SELECT u.user_id, u.username, u.is_active`).
-		WithArgs("empty").
-		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "is_active"}))
-	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).
-		WithArgs("empty").
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		WithArgs("empty", StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "is_active", "review_weight", "max_open_reviews", "open_reviews", "role"}))
+	expectTeamSkills(mock, "empty")
+	expectFallbackTeams(mock, "empty")
 
 	team, err := store.GetTeam(context.Background(), "empty")
 	if err != nil {
@@ -574,12 +838,7 @@ func TestPickCandidatesTrimsLimit(t *testing.T) {
 	store, mock, cleanup := newMockStore(t)
 	defer cleanup()
 
-	rows := sqlmock.NewRows([]string{"user_id"}).
-		AddRow("u1").
-		AddRow("u2").
-		AddRow("u3")
-	mock.ExpectQuery(`SELECT user_id FROM users`).WithArgs("backend", "").
-		WillReturnRows(rows)
+	expectCandidates(mock, "backend", "u1", "u2", "u3")
 
 	cands, err := store.pickCandidates(context.Background(), store.db, "backend", "", nil, 2)
 	if err != nil {
@@ -590,7 +849,7 @@ func TestPickCandidatesTrimsLimit(t *testing.T) {
 	}
 }
 
-func TestFetchPRTxNotFound(t *testing.T) {
+func TestFetchPRNotFound(t *testing.T) {
 	store, mock, cleanup := newMockStore(t)
 	defer cleanup()
 
@@ -604,7 +863,7 @@ func TestFetchPRTxNotFound(t *testing.T) {
 	if err != nil {
 		t.Fatalf("begin: %v", err)
 	}
-	if _, err := store.fetchPRTx(context.Background(), tx, "pr404"); !errors.Is(err, ErrPRNotFound) {
+	if _, err := store.fetchPR(context.Background(), tx, "pr404"); !errors.Is(err, ErrPRNotFound) {
 		t.Fatalf("expected ErrPRNotFound, got %v", err)
 	}
 	_ = tx.Rollback()
@@ -620,8 +879,13 @@ func TestRunMigrations(t *testing.T) {
 	}
 	defer db.Close()
 	mock.MatchExpectationsInOrder(false)
-	mock.ExpectExec(`(?s).*`).WillReturnResult(sqlmock.NewResult(0, 0))
-	mock.ExpectExec(`(?s).*`).WillReturnResult(sqlmock.NewResult(0, 0))
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		t.Fatalf("read migrations: %v", err)
+	}
+	for range entries {
+		mock.ExpectExec(`(?s).*`).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
 
 	if err := RunMigrations(context.Background(), db); err != nil {
 		t.Fatalf("RunMigrations error: %v", err)
@@ -654,9 +918,10 @@ func TestMergePRNotFound(t *testing.T) {
 	store, mock, cleanup := newMockStore(t)
 	defer cleanup()
 
-	mock.ExpectQuery(`UPDATE pull_requests`).
-		WithArgs("pr404", StatusMerged).
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT author_id, status FROM pull_requests WHERE pr_id=\$1 FOR UPDATE`).WithArgs("pr404").
 		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
 
 	_, err := store.MergePR(context.Background(), "pr404")
 	if !errors.Is(err, ErrPRNotFound) {
@@ -675,10 +940,8 @@ func TestCreatePRPickCandidatesError(t *testing.T) {
 	mock.ExpectQuery(`SELECT user_id, team_name FROM users WHERE user_id=`).
 		WithArgs("author").
 		WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_name"}).AddRow("author", "backend"))
-	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).
-		WithArgs("backend").
-		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
-	mock.ExpectQuery(`SELECT user_id FROM users`).WithArgs("backend", "author").
+	expectTeamSettings(mock, "backend")
+	mock.ExpectQuery(`SELECT u.user_id, u.review_weight`).WithArgs("backend", "author", StatusOpen).
 		WillReturnError(errors.New("db error"))
 	mock.ExpectRollback()
 
@@ -838,8 +1101,9 @@ func TestBuildTeamQueryError(t *testing.T) {
 	store, mock, cleanup := newMockStore(t)
 	defer cleanup()
 
+	expectTeamSettings(mock, "backend")
 	mock.ExpectQuery(`SELECT u.user_id, u.username, u.is_active`).
-		WithArgs("backend").
+		WithArgs("backend", StatusOpen).
 		WillReturnError(errors.New("query failed"))
 
 	if _, err := store.GetTeam(context.Background(), "backend"); err == nil {
@@ -870,10 +1134,17 @@ func TestListReviewersError(t *testing.T) {
 		AddRow("u1").
 		AddRow("u2").
 		RowError(1, errors.New("scan error"))
+	mock.ExpectBegin()
 	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers`).WithArgs("pr1").
 		WillReturnRows(rows)
+	mock.ExpectRollback()
 
-	if _, err := store.listReviewers(context.Background(), "pr1"); err == nil {
+	tx, err := store.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := store.listReviewersTx(context.Background(), tx, "pr1"); err == nil {
 		t.Fatal("expected error")
 	}
 }
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// AssignmentPreview lists the reviewers CreatePR would assign right now.
+type AssignmentPreview struct {
+	AuthorID          string                 `json:"author_id"`
+	AssignedReviewers []string               `json:"assigned_reviewers"`
+	FallbackReviewers []string               `json:"fallback_reviewers,omitempty"`
+	UncoveredTags     []string               `json:"uncovered_tags,omitempty"`
+	OwnerReviewers    []string               `json:"owner_reviewers,omitempty"`
+	UncoveredPaths    []string               `json:"uncovered_paths,omitempty"`
+	SkippedReviewers  []string               `json:"skipped_reviewers,omitempty"`
+	MissingReviewers  int                    `json:"missing_reviewers,omitempty"`
+	Explanation       []SelectionExplanation `json:"explanation,omitempty"`
+}
+
+// PreviewPR runs the CreatePR selection in a transaction that is always rolled
+// back, so nothing is persisted. Randomized strategies may pick differently on
+// the actual CreatePR call.
+func (s *Store) PreviewPR(ctx context.Context, payload CreatePRPayload) (*AssignmentPreview, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	picked, err := s.selectForNewPR(ctx, tx, payload)
+	if err != nil {
+		return nil, err
+	}
+	preview := &AssignmentPreview{
+		AuthorID:          payload.Author,
+		AssignedReviewers: picked.reviewers,
+		FallbackReviewers: picked.fromFallback,
+		UncoveredTags:     picked.uncoveredTags,
+		OwnerReviewers:    picked.fromOwners,
+		UncoveredPaths:    picked.uncoveredPaths,
+		SkippedReviewers:  picked.skipped,
+		MissingReviewers:  picked.missing,
+	}
+	if preview.AssignedReviewers == nil {
+		preview.AssignedReviewers = []string{}
+	}
+	if payload.Explain {
+		preview.Explanation = picked.explanation
+	}
+	return preview, nil
+}
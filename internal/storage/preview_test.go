@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestPreviewPRRollsBack(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.MatchExpectationsInOrder(true)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT user_id, team_name FROM users WHERE user_id=`).
+		WithArgs(authorID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_name"}).AddRow(authorID, teamBackend))
+	expectCandidates(mock, teamBackend, "u1", "u2", "u3")
+	mock.ExpectRollback()
+
+	preview, err := store.PreviewPR(context.Background(), CreatePRPayload{Author: authorID})
+	if err != nil {
+		t.Fatalf("PreviewPR error: %v", err)
+	}
+	if preview.AuthorID != authorID || len(preview.AssignedReviewers) != DefaultMaxReviewers {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestPreviewPRValidatesReviewersCount(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT user_id, team_name FROM users WHERE user_id=`).
+		WithArgs(authorID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_name"}).AddRow(authorID, teamBackend))
+	expectTeamSettings(mock, teamBackend)
+	mock.ExpectRollback()
+
+	count := 5
+	_, err := store.PreviewPR(context.Background(), CreatePRPayload{Author: authorID, ReviewersCount: &count})
+	if !errors.Is(err, ErrInvalidReviewerCount) {
+		t.Fatalf("expected ErrInvalidReviewerCount, got %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Page size bounds of ListPRs.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+)
+
+// PR list orders: SortCreated lists the newest PRs first, SortID orders by
+// pull_request_id.
+const (
+	SortCreated = "created_at"
+	SortID      = "pr_id"
+)
+
+var prStatuses = []string{StatusDraft, StatusOpen, StatusMerged, StatusClosed}
+
+// PRFilter selects the PRs returned by ListPRs; empty fields do not filter.
+// TeamName matches the team the author was in when the PR was created. Time
+// ranges include From and exclude To.
+type PRFilter struct {
+	Status      string
+	AuthorID    string
+	ReviewerID  string
+	TeamName    string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MergedFrom  *time.Time
+	MergedTo    *time.Time
+	// Sort is SortCreated or SortID; empty picks the default order of the caller.
+	Sort  string
+	Limit int
+	// Cursor is the NextCursor of the previous page.
+	Cursor string
+}
+
+// PRPage is one page of PRs. NextCursor is empty on the last page.
+type PRPage struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
+
+// prCursor is the keyset position of the last PR of a page. Sort ties the
+// cursor to the order it was issued for.
+type prCursor struct {
+	Sort      string    `json:"sort"`
+	CreatedAt time.Time `json:"created_at"`
+	ID        string    `json:"pull_request_id"`
+}
+
+func encodeCursor(c prCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s, sort string) (prCursor, error) {
+	var c prCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(raw, &c)
+	}
+	if err != nil || c.ID == "" {
+		return prCursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidFilter)
+	}
+	if c.Sort != sort {
+		return prCursor{}, fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidFilter, c.Sort)
+	}
+	return c, nil
+}
+
+func validatePRFilter(f PRFilter) error {
+	if f.Status != "" && !slices.Contains(prStatuses, f.Status) {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidFilter, f.Status)
+	}
+	if f.Sort != "" && f.Sort != SortCreated && f.Sort != SortID {
+		return fmt.Errorf("%w: sort must be %s or %s", ErrInvalidFilter, SortCreated, SortID)
+	}
+	if f.Limit < 0 || f.Limit > MaxPageSize {
+		return fmt.Errorf("%w: limit must be within [1, %d]", ErrInvalidFilter, MaxPageSize)
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && !f.CreatedFrom.Before(*f.CreatedTo) {
+		return fmt.Errorf("%w: created_from must be before created_to", ErrInvalidFilter)
+	}
+	if f.MergedFrom != nil && f.MergedTo != nil && !f.MergedFrom.Before(*f.MergedTo) {
+		return fmt.Errorf("%w: merged_from must be before merged_to", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// GetPR returns a PR with its reviewers and their verdicts.
+func (s *Store) GetPR(ctx context.Context, id string) (*PullRequest, error) {
+	return s.fetchPR(ctx, s.db, id)
+}
+
+// ListPRs returns the PRs matching filter, newest first unless filter asks
+// for SortID. Pagination is keyset-based, so PRs created while paging do not
+// shift later pages.
+func (s *Store) ListPRs(ctx context.Context, filter PRFilter) (*PRPage, error) {
+	if err := validatePRFilter(filter); err != nil {
+		return nil, err
+	}
+	if filter.Sort == "" {
+		filter.Sort = SortCreated
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPageSize
+	}
+	return s.listPRs(ctx, filter)
+}
+
+// UserReviews returns the PRs the user is currently assigned to review. By
+// default every such PR is returned ordered by id; a limit turns on paging.
+func (s *Store) UserReviews(ctx context.Context, userID string, filter PRFilter) (*PRPage, error) {
+	if err := validatePRFilter(filter); err != nil {
+		return nil, err
+	}
+	var exists bool
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)`, userID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	if filter.Sort == "" {
+		filter.Sort = SortID
+	}
+	filter.ReviewerID = userID
+	return s.listPRs(ctx, filter)
+}
+
+// listPRs runs a validated filter; a zero Limit returns every matching PR.
+func (s *Store) listPRs(ctx context.Context, filter PRFilter) (*PRPage, error) {
+	var conds []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filter.Status != "" {
+		conds = append(conds, "pr.status = "+arg(filter.Status))
+	}
+	if filter.AuthorID != "" {
+		conds = append(conds, "pr.author_id = "+arg(filter.AuthorID))
+	}
+	if filter.ReviewerID != "" {
+		conds = append(conds, "EXISTS (SELECT 1 FROM assigned_reviewers r WHERE r.pr_id = pr.pr_id AND r.user_id = "+
+			arg(filter.ReviewerID)+")")
+	}
+	if filter.TeamName != "" {
+		conds = append(conds, "pr.team_name = "+arg(filter.TeamName))
+	}
+	if filter.CreatedFrom != nil {
+		conds = append(conds, "pr.created_at >= "+arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conds = append(conds, "pr.created_at < "+arg(*filter.CreatedTo))
+	}
+	if filter.MergedFrom != nil {
+		conds = append(conds, "pr.merged_at >= "+arg(*filter.MergedFrom))
+	}
+	if filter.MergedTo != nil {
+		conds = append(conds, "pr.merged_at < "+arg(*filter.MergedTo))
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor, filter.Sort)
+		if err != nil {
+			return nil, err
+		}
+		if filter.Sort == SortID {
+			conds = append(conds, "pr.pr_id > "+arg(cursor.ID))
+		} else {
+			conds = append(conds, "(pr.created_at, pr.pr_id) < ("+arg(cursor.CreatedAt)+", "+arg(cursor.ID)+")")
+		}
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	order := "%[1]s.created_at DESC, %[1]s.pr_id DESC"
+	if filter.Sort == SortID {
+		order = "%[1]s.pr_id"
+	}
+	limit := ""
+	if filter.Limit > 0 {
+		// One extra row tells whether there is a next page.
+		limit = "LIMIT " + arg(filter.Limit+1)
+	}
+	// Reviewers of the page are joined in the same query.
+	rows, err := s.db.QueryContext(ctx, `
+WITH page AS (
+    SELECT pr.pr_id, pr.pr_name, COALESCE(pr.author_id, '') AS author_id, pr.status, pr.created_at, pr.merged_at
+    FROM pull_requests pr
+    `+where+`
+    ORDER BY `+fmt.Sprintf(order, "pr")+`
+    `+limit+`
+)
+SELECT page.pr_id, page.pr_name, page.author_id, page.status, page.created_at, page.merged_at,
+       ar.user_id, ar.verdict, ar.verdict_at
+FROM page
+LEFT JOIN assigned_reviewers ar ON ar.pr_id = page.pr_id
+ORDER BY `+fmt.Sprintf(order, "page")+`, ar.user_id
+`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	page := &PRPage{PullRequests: make([]PullRequest, 0)}
+	for rows.Next() {
+		var pr PullRequest
+		var reviewer, verdict sql.NullString
+		var verdictAt sql.NullTime
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt,
+			&reviewer, &verdict, &verdictAt); err != nil {
+			return nil, err
+		}
+		n := len(page.PullRequests)
+		if n == 0 || page.PullRequests[n-1].ID != pr.ID {
+			pr.AssignedReviewers = []string{}
+			page.PullRequests = append(page.PullRequests, pr)
+			n++
+		}
+		if !reviewer.Valid {
+			continue
+		}
+		last := &page.PullRequests[n-1]
+		last.AssignedReviewers = append(last.AssignedReviewers, reviewer.String)
+		if verdict.Valid {
+			last.Reviews = append(last.Reviews,
+				Review{UserID: reviewer.String, Verdict: verdict.String, SubmittedAt: verdictAt.Time})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if filter.Limit > 0 && len(page.PullRequests) > filter.Limit {
+		page.PullRequests = page.PullRequests[:filter.Limit]
+		last := page.PullRequests[filter.Limit-1]
+		page.NextCursor = encodeCursor(prCursor{Sort: filter.Sort, CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return page, nil
+}
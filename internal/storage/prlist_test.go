@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var prListColumns = []string{
+	"pr_id", "pr_name", "author_id", "status", "created_at", "merged_at", "user_id", "verdict", "verdict_at",
+}
+
+func TestListPRsPaginates(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	newer := time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC)
+	older := newer.Add(-time.Hour)
+	mock.ExpectQuery(`WITH page AS \(.*WHERE pr.status = \$1 AND EXISTS \(SELECT 1 FROM assigned_reviewers r WHERE r.pr_id = pr.pr_id AND r.user_id = \$2\)\s+ORDER BY pr.created_at DESC, pr.pr_id DESC\s+LIMIT \$3`).
+		WithArgs(StatusOpen, "u1", 2).
+		WillReturnRows(sqlmock.NewRows(prListColumns).
+			AddRow("pr2", "second", authorID, StatusOpen, newer, nil, "u1", VerdictApproved, newer).
+			AddRow("pr2", "second", authorID, StatusOpen, newer, nil, "u2", nil, nil).
+			AddRow("pr1", "first", authorID, StatusOpen, older, nil, "u1", nil, nil))
+
+	page, err := store.ListPRs(context.Background(), PRFilter{Status: StatusOpen, ReviewerID: "u1", Limit: 1})
+	if err != nil {
+		t.Fatalf("ListPRs error: %v", err)
+	}
+	if len(page.PullRequests) != 1 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	pr := page.PullRequests[0]
+	if pr.ID != "pr2" || !reflect.DeepEqual(pr.AssignedReviewers, []string{"u1", "u2"}) || len(pr.Reviews) != 1 {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+	cursor, err := decodeCursor(page.NextCursor, SortCreated)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if cursor.ID != "pr2" || !cursor.CreatedAt.Equal(newer) {
+		t.Fatalf("unexpected cursor: %+v", cursor)
+	}
+
+	mock.ExpectQuery(`WITH page AS \(.*WHERE \(pr.created_at, pr.pr_id\) < \(\$1, \$2\)`).
+		WithArgs(sqlmock.AnyArg(), "pr2", DefaultPageSize+1).
+		WillReturnRows(sqlmock.NewRows(prListColumns).
+			AddRow("pr1", "first", authorID, StatusOpen, older, nil, nil, nil, nil))
+
+	page, err = store.ListPRs(context.Background(), PRFilter{Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("ListPRs error: %v", err)
+	}
+	if len(page.PullRequests) != 1 || page.NextCursor != "" || len(page.PullRequests[0].AssignedReviewers) != 0 {
+		t.Fatalf("unexpected last page: %+v", page)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListPRsFiltersByTeamAtCreation(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	created := time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`WITH page AS \(\s+SELECT .*\s+FROM pull_requests pr\s+WHERE pr.team_name = \$1\s+ORDER BY`).
+		WithArgs(teamBackend, DefaultPageSize+1).
+		WillReturnRows(sqlmock.NewRows(prListColumns).
+			AddRow("pr1", "first", authorID, StatusMerged, created, created, nil, nil, nil))
+
+	page, err := store.ListPRs(context.Background(), PRFilter{TeamName: teamBackend})
+	if err != nil {
+		t.Fatalf("ListPRs error: %v", err)
+	}
+	if len(page.PullRequests) != 1 || page.PullRequests[0].ID != "pr1" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListPRsRejectsInvalidFilter(t *testing.T) {
+	store, _, cleanup := newMockStore(t)
+	defer cleanup()
+
+	from := time.Now()
+	to := from.Add(-time.Hour)
+	for _, filter := range []PRFilter{
+		{Status: "REVIEWING"},
+		{Limit: -1},
+		{Limit: MaxPageSize + 1},
+		{CreatedFrom: &from, CreatedTo: &to},
+		{MergedFrom: &from, MergedTo: &from},
+		{Cursor: "not-a-cursor"},
+		{Sort: "name"},
+		{Sort: SortID, Cursor: encodeCursor(prCursor{Sort: SortCreated, ID: "pr1"})},
+	} {
+		if _, err := store.ListPRs(context.Background(), filter); !errors.Is(err, ErrInvalidFilter) {
+			t.Fatalf("filter %+v: expected ErrInvalidFilter, got %v", filter, err)
+		}
+	}
+}
+
+func TestUserReviewsPaginatesByID(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM users WHERE user_id=`).WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`WITH page AS \(.*WHERE pr.status = \$1 AND .*r.user_id = \$2\) AND pr.pr_id > \$3\s+ORDER BY pr.pr_id\s+LIMIT \$4`).
+		WithArgs(StatusOpen, "u1", "pr1", 2).
+		WillReturnRows(sqlmock.NewRows(prListColumns).
+			AddRow("pr2", "second", authorID, StatusOpen, time.Now(), nil, "u1", nil, nil).
+			AddRow("pr2", "second", authorID, StatusOpen, time.Now(), nil, "u2", nil, nil).
+			AddRow("pr3", "third", authorID, StatusOpen, time.Now(), nil, "u1", nil, nil))
+
+	page, err := store.UserReviews(context.Background(), "u1", PRFilter{
+		Status: StatusOpen,
+		Limit:  1,
+		Cursor: encodeCursor(prCursor{Sort: SortID, ID: "pr1"}),
+	})
+	if err != nil {
+		t.Fatalf("UserReviews error: %v", err)
+	}
+	if len(page.PullRequests) != 1 || !reflect.DeepEqual(page.PullRequests[0].AssignedReviewers, []string{"u1", "u2"}) {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	if cursor, err := decodeCursor(page.NextCursor, SortID); err != nil || cursor.ID != "pr2" {
+		t.Fatalf("unexpected cursor %q: %v", page.NextCursor, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetPR(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	expectFetchPRStatus(mock, StatusOpen, "u1", "u2")
+
+	pr, err := store.GetPR(context.Background(), "pr1")
+	if err != nil {
+		t.Fatalf("GetPR error: %v", err)
+	}
+	if pr.ID != "pr1" || len(pr.AssignedReviewers) != 2 {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+}
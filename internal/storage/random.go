@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Random is the source of randomness used by selection strategies. A Store
+// shares one instance between concurrent requests, so it must be safe for
+// concurrent use.
+type Random interface {
+	Intn(n int) int
+	Shuffle(n int, swap func(i, j int))
+}
+
+// lockedRand serializes access to a *rand.Rand, which is not goroutine-safe.
+type lockedRand struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewSeededRandom returns a concurrency-safe Random producing the same
+// sequence for the same seed.
+func NewSeededRandom(seed int64) Random {
+	// pseudo-randomness is fine for reviewer selection
+	return &lockedRand{rnd: rand.New(rand.NewSource(seed))} //nolint:gosec
+}
+
+func (l *lockedRand) Intn(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rnd.Intn(n)
+}
+
+func (l *lockedRand) Shuffle(n int, swap func(i, j int)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rnd.Shuffle(n, swap)
+}
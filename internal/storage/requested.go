@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// validateReviewerRequests checks the reviewers the author asked for or
+// against: ids must be non-empty and listed once across both lists, and the
+// author cannot request themselves.
+func validateReviewerRequests(author string, requested, excluded []string) error {
+	if slices.Contains(requested, author) {
+		return fmt.Errorf("%w: author %q cannot review their own PR", ErrInvalidReviewers, author)
+	}
+	seen := make(map[string]struct{}, len(requested)+len(excluded))
+	for _, id := range slices.Concat(requested, excluded) {
+		if id == "" {
+			return fmt.Errorf("%w: empty user_id", ErrInvalidReviewers)
+		}
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("%w: %q is listed more than once", ErrInvalidReviewers, id)
+		}
+		seen[id] = struct{}{}
+	}
+	return nil
+}
+
+// pickRequested assigns the reviewers the author asked for. Inactive ones are
+// skipped; absences and capacity limits do not apply since the author chose
+// them explicitly. Requested reviewers count towards the role policy and the
+// required tags like any other pick.
+func (s *Store) pickRequested(
+	ctx context.Context,
+	q querier,
+	settings TeamSettings,
+	requested []string,
+	rules selectionRules,
+) (selection, error) {
+	result := selection{uncoveredTags: rules.requiredTags}
+	if len(requested) == 0 {
+		return result, nil
+	}
+	explanation := SelectionExplanation{
+		TeamName:  settings.TeamName,
+		Strategy:  s.strategyFor(settings.ReviewerStrategy),
+		Requested: true,
+		Pool:      make([]string, 0, len(requested)),
+		Excluded:  make([]Exclusion, 0),
+	}
+	skills := make(map[string][]string)
+	for _, id := range requested {
+		var active bool
+		var role string
+		err := q.QueryRowContext(ctx, `SELECT is_active, role FROM users WHERE user_id=$1`, id).Scan(&active, &role)
+		if errors.Is(err, sql.ErrNoRows) {
+			return selection{}, fmt.Errorf("%w: unknown user %q", ErrInvalidReviewers, id)
+		}
+		if err != nil {
+			return selection{}, err
+		}
+		if !active {
+			result.skipped = append(result.skipped, id)
+			explanation.Excluded = append(explanation.Excluded, Exclusion{UserID: id, Reason: ExcludedInactive})
+			continue
+		}
+		explanation.Pool = append(explanation.Pool, id)
+		result.reviewers = append(result.reviewers, id)
+		if len(explanation.PolicyReviewers) < rules.roleCount && roleAtLeast(role, rules.role) {
+			explanation.PolicyReviewers = append(explanation.PolicyReviewers, id)
+		}
+		if len(rules.requiredTags) > 0 {
+			if skills[id], err = loadUserSkills(ctx, q, id); err != nil {
+				return selection{}, err
+			}
+		}
+	}
+	if len(rules.requiredTags) > 0 {
+		covered := tagsCoveredBy(result.reviewers, skills, rules.requiredTags)
+		if len(covered) > 0 {
+			explanation.CoveredTags = covered
+		}
+		result.uncoveredTags = uncoveredTags(rules.requiredTags, covered)
+	}
+	explanation.Selected = append([]string{}, result.reviewers...)
+	result.policyReviewers = len(explanation.PolicyReviewers)
+	result.explanation = []SelectionExplanation{explanation}
+	return result, nil
+}
+
+func userSet(ids []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectRequestedUser(mock sqlmock.Sqlmock, userID string, active bool, role string) {
+	mock.ExpectQuery(`SELECT is_active, role FROM users WHERE user_id=`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"is_active", "role"}).AddRow(active, role))
+}
+
+func TestValidateReviewerRequests(t *testing.T) {
+	cases := []struct {
+		name      string
+		requested []string
+		excluded  []string
+		ok        bool
+	}{
+		{"empty", nil, nil, true},
+		{"distinct", []string{"u1"}, []string{"u2"}, true},
+		{"author requested", []string{authorID}, nil, false},
+		{"duplicate", []string{"u1", "u1"}, nil, false},
+		{"requested and excluded", []string{"u1"}, []string{"u1"}, false},
+		{"empty id", nil, []string{""}, false},
+	}
+	for _, tc := range cases {
+		err := validateReviewerRequests(authorID, tc.requested, tc.excluded)
+		if tc.ok != (err == nil) {
+			t.Fatalf("%s: err = %v", tc.name, err)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidReviewers) {
+			t.Fatalf("%s: expected ErrInvalidReviewers, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestCreatePRHonorsRequestedAndExcludedReviewers(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 1, 2)
+	expectRequestedUser(mock, "outsider", true, RoleMember)
+	expectRequestedUser(mock, "retired", false, RoleMember)
+	expectCandidateRows(mock, teamBackend, "u1", "u2")
+	mock.ExpectExec(`INSERT INTO pull_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "outsider").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "u2").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assignment_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assignment_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	pr, err := store.CreatePR(context.Background(), CreatePRPayload{
+		ID: "pr1", Name: "feature", Author: authorID, Explain: true,
+		RequestedReviewers: []string{"outsider", "retired"},
+		ExcludedReviewers:  []string{"u1"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePR error: %v", err)
+	}
+	if want := []string{"outsider", "u2"}; !reflect.DeepEqual(pr.AssignedReviewers, want) {
+		t.Fatalf("reviewers = %v, want %v", pr.AssignedReviewers, want)
+	}
+	if !reflect.DeepEqual(pr.SkippedReviewers, []string{"retired"}) {
+		t.Fatalf("skipped = %v", pr.SkippedReviewers)
+	}
+	if len(pr.Explanation) != 2 || !pr.Explanation[0].Requested {
+		t.Fatalf("unexpected explanation: %+v", pr.Explanation)
+	}
+	wantExcluded := []Exclusion{{UserID: "u1", Reason: ExcludedByAuthor}}
+	if !reflect.DeepEqual(pr.Explanation[1].Excluded, wantExcluded) {
+		t.Fatalf("excluded = %+v, want %+v", pr.Explanation[1].Excluded, wantExcluded)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCreatePRRequestedReviewerSatisfiesPolicy(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectPolicyLookups(mock, 2, RoleSenior, 1)
+	expectRequestedUser(mock, "lead", true, RoleLead)
+	expectCandidateRows(mock, teamBackend, "u1")
+	mock.ExpectExec(`INSERT INTO pull_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	for i := 0; i < 2; i++ {
+		mock.ExpectExec(`INSERT INTO assigned_reviewers`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO assignment_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	pr, err := store.CreatePR(context.Background(), CreatePRPayload{
+		ID: "pr1", Name: "feature", Author: authorID, RequestedReviewers: []string{"lead"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePR error: %v", err)
+	}
+	if want := []string{"lead", "u1"}; !reflect.DeepEqual(pr.AssignedReviewers, want) {
+		t.Fatalf("reviewers = %v, want %v", pr.AssignedReviewers, want)
+	}
+}
+
+func TestCreatePRRejectsInvalidRequestedReviewers(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 1, 2)
+	mock.ExpectQuery(`SELECT is_active, role FROM users WHERE user_id=`).
+		WithArgs("ghost").
+		WillReturnRows(sqlmock.NewRows([]string{"is_active", "role"}))
+	mock.ExpectRollback()
+
+	_, err := store.CreatePR(context.Background(), CreatePRPayload{
+		ID: "pr1", Name: "feature", Author: authorID, RequestedReviewers: []string{"ghost"},
+	})
+	if !errors.Is(err, ErrInvalidReviewers) {
+		t.Fatalf("expected ErrInvalidReviewers, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 1, 2)
+	mock.ExpectRollback()
+	_, err = store.CreatePR(context.Background(), CreatePRPayload{
+		ID: "pr1", Name: "feature", Author: authorID, RequestedReviewers: []string{"u1", "u2", "u3"},
+	})
+	if !errors.Is(err, ErrInvalidReviewerCount) {
+		t.Fatalf("expected ErrInvalidReviewerCount, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ReviewerPayload names a reviewer to add to or remove from an open PR.
+type ReviewerPayload struct {
+	PRID   string `json:"pull_request_id"`
+	UserID string `json:"user_id"`
+}
+
+// AddReviewer assigns one more reviewer to an open PR. The reviewer is not
+// bound by the reviewer count of the author's team, but must belong to that
+// team or one of its fallback teams and pass the checks automatic selection
+// applies: active, not out of office, below the open review limit and not the
+// author.
+func (s *Store) AddReviewer(ctx context.Context, payload ReviewerPayload) (*PullRequest, error) {
+	return s.changeReviewers(ctx, payload, s.addReviewerOnce)
+}
+
+// RemoveReviewer drops a reviewer from an open PR without a replacement. It
+// refuses to remove a reviewer the team role policy still relies on.
+func (s *Store) RemoveReviewer(ctx context.Context, payload ReviewerPayload) (*PullRequest, error) {
+	return s.changeReviewers(ctx, payload, s.removeReviewerOnce)
+}
+
+func (s *Store) changeReviewers(
+	ctx context.Context,
+	payload ReviewerPayload,
+	once func(context.Context, *sql.Tx, ReviewerPayload) error,
+) (*PullRequest, error) {
+	for attempts := 0; attempts < 3; attempts++ {
+		pr, err := s.changeReviewersOnce(ctx, payload, once)
+		if err == nil {
+			return pr, nil
+		}
+		if isSerializationError(err) && attempts < 2 {
+			time.Sleep(time.Duration(attempts+1) * 10 * time.Millisecond)
+			continue
+		}
+		return nil, err
+	}
+	return nil, fmt.Errorf("unreachable")
+}
+
+func (s *Store) changeReviewersOnce(
+	ctx context.Context,
+	payload ReviewerPayload,
+	once func(context.Context, *sql.Tx, ReviewerPayload) error,
+) (*PullRequest, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	if err := once(ctx, tx, payload); err != nil {
+		return nil, err
+	}
+	updated, err := s.fetchPR(ctx, tx, payload.PRID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *Store) addReviewerOnce(ctx context.Context, tx *sql.Tx, payload ReviewerPayload) error {
+	prMeta, err := s.loadPRMeta(ctx, tx, payload.PRID)
+	if err != nil {
+		return err
+	}
+	var team sql.NullString
+	if err := tx.QueryRowContext(ctx,
+		`SELECT team_name FROM users WHERE user_id=$1`, payload.UserID).Scan(&team); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if payload.UserID == prMeta.authorID {
+		return fmt.Errorf("%w: %q is the PR author", ErrIneligibleReviewer, payload.UserID)
+	}
+	if slices.Contains(prMeta.currentReviewers, payload.UserID) {
+		return ErrAlreadyAssigned
+	}
+	if !team.Valid {
+		return fmt.Errorf("%w: %q has no team", ErrIneligibleReviewer, payload.UserID)
+	}
+	authorTeam, err := lookupAuthorTeam(ctx, tx, prMeta.authorID)
+	if err != nil {
+		return err
+	}
+	if _, err := checkReviewerTeam(ctx, tx, authorTeam, team.String); err != nil {
+		return err
+	}
+	settings, err := loadTeamSettings(ctx, tx, team.String)
+	if err != nil {
+		return err
+	}
+	members, err := loadTeamMembers(ctx, tx, team.String)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(members, func(m teamMember) bool { return m.UserID == payload.UserID })
+	if i < 0 {
+		return ErrUserNotFound
+	}
+	if reason := exclusionReason(members[i], prMeta.authorID, nil, settings.DefaultMaxOpenReviews); reason != "" {
+		return fmt.Errorf("%w: %q is %s", ErrIneligibleReviewer, payload.UserID, reason)
+	}
+	return s.assignReviewer(ctx, tx, payload.PRID, payload.UserID, ReasonReviewerAdded, ActorSystem)
+}
+
+func (s *Store) removeReviewerOnce(ctx context.Context, tx *sql.Tx, payload ReviewerPayload) error {
+	prMeta, err := s.loadPRMeta(ctx, tx, payload.PRID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(prMeta.currentReviewers, payload.UserID) {
+		return ErrNotAssigned
+	}
+	rules, err := reassignPolicy(ctx, tx, payload.PRID, payload.UserID)
+	if err != nil {
+		return err
+	}
+	if rules.roleCount > 0 {
+		return ErrPolicyUnsatisfiable
+	}
+	return s.unassignReviewer(ctx, tx, payload.PRID, payload.UserID, ReasonReviewerRemoved, ActorSystem)
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectPRMeta(mock sqlmock.Sqlmock, status string, reviewers ...string) {
+	mock.ExpectQuery(`SELECT author_id, status FROM pull_requests WHERE pr_id=\$1 FOR UPDATE`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "status"}).AddRow("author", status))
+	if status == StatusMerged {
+		return
+	}
+	rows := sqlmock.NewRows([]string{"user_id"})
+	for _, id := range reviewers {
+		rows.AddRow(id)
+	}
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers`).WithArgs("pr1").WillReturnRows(rows)
+}
+
+func expectFetchPR(mock sqlmock.Sqlmock, reviewers ...string) {
+	mock.ExpectQuery(`SELECT pr_id, pr_name, author_id, status, created_at, merged_at FROM pull_requests`).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "pr_name", "author_id", "status", "created_at", "merged_at"}).
+			AddRow("pr1", "feature", "author", StatusOpen, time.Now(), nil))
+	mock.ExpectQuery(reviewsPattern).WithArgs("pr1").WillReturnRows(reviewRows(reviewers...))
+}
+
+// expectAddReviewerLookups registers the lookups AddReviewer issues for a
+// candidate of team once the PR and the author have been checked.
+func expectAddReviewerLookups(mock sqlmock.Sqlmock, userID, team string) {
+	mock.ExpectQuery(`SELECT team_name FROM users WHERE user_id=`).WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow(team))
+	mock.ExpectQuery(`SELECT user_id, team_name FROM users WHERE user_id=`).WithArgs("author").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_name"}).AddRow("author", teamBackend))
+}
+
+func TestAddReviewer(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectPRMeta(mock, StatusOpen, "u1", "u2")
+	expectAddReviewerLookups(mock, "u3", teamBackend)
+	expectTeamSettings(mock, teamBackend)
+	expectCandidateRows(mock, teamBackend, "u1", "u2", "u3")
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "u3").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "u3", EventAssigned, ReasonReviewerAdded)
+	expectFetchPR(mock, "u1", "u2", "u3")
+	mock.ExpectCommit()
+
+	pr, err := store.AddReviewer(context.Background(), ReviewerPayload{PRID: "pr1", UserID: "u3"})
+	if err != nil {
+		t.Fatalf("AddReviewer error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 3 {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestAddReviewerFromFallbackTeam(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectPRMeta(mock, StatusOpen, "u1")
+	expectAddReviewerLookups(mock, "p1", "platform")
+	expectFallbackTeams(mock, teamBackend, "platform")
+	expectTeamSettings(mock, "platform")
+	expectCandidateRows(mock, "platform", "p1")
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "p1").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "p1", EventAssigned, ReasonReviewerAdded)
+	expectFetchPR(mock, "p1", "u1")
+	mock.ExpectCommit()
+
+	if _, err := store.AddReviewer(context.Background(), ReviewerPayload{PRID: "pr1", UserID: "p1"}); err != nil {
+		t.Fatalf("AddReviewer error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestAddReviewerValidation(t *testing.T) {
+	member := func(id string, active, absent bool, maxOpen, open int) func(sqlmock.Sqlmock) {
+		return func(mock sqlmock.Sqlmock) {
+			expectAddReviewerLookups(mock, id, teamBackend)
+			expectTeamSettings(mock, teamBackend)
+			mock.ExpectQuery(membersPattern).WithArgs(teamBackend, StatusOpen).
+				WillReturnRows(sqlmock.NewRows(memberColumns).AddRow(id, active, 1, maxOpen, absent, nil, open, RoleMember))
+		}
+	}
+	cases := []struct {
+		name    string
+		status  string
+		userID  string
+		expect  func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{"merged", StatusMerged, "u3", func(sqlmock.Sqlmock) {}, ErrPRMerged},
+		{"author", StatusOpen, "author", func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`SELECT team_name FROM users WHERE user_id=`).WithArgs("author").
+				WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow(teamBackend))
+		}, ErrIneligibleReviewer},
+		{"assigned", StatusOpen, "u1", func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`SELECT team_name FROM users WHERE user_id=`).WithArgs("u1").
+				WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow(teamBackend))
+		}, ErrAlreadyAssigned},
+		{"unknown", StatusOpen, "ghost", func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`SELECT team_name FROM users WHERE user_id=`).WithArgs("ghost").
+				WillReturnRows(sqlmock.NewRows([]string{"team_name"}))
+		}, ErrUserNotFound},
+		{"no team", StatusOpen, "u3", func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`SELECT team_name FROM users WHERE user_id=`).WithArgs("u3").
+				WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow(nil))
+		}, ErrIneligibleReviewer},
+		{"other team", StatusOpen, "d1", func(mock sqlmock.Sqlmock) {
+			expectAddReviewerLookups(mock, "d1", "docs")
+			expectFallbackTeams(mock, teamBackend, "platform")
+		}, ErrIneligibleReviewer},
+		{"inactive", StatusOpen, "u3", member("u3", false, false, 0, 0), ErrIneligibleReviewer},
+		{"out of office", StatusOpen, "u3", member("u3", true, true, 0, 0), ErrIneligibleReviewer},
+		{"at capacity", StatusOpen, "u3", member("u3", true, false, 2, 2), ErrIneligibleReviewer},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store, mock, cleanup := newMockStore(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			expectPRMeta(mock, tc.status, "u1")
+			tc.expect(mock)
+			mock.ExpectRollback()
+
+			_, err := store.AddReviewer(context.Background(), ReviewerPayload{PRID: "pr1", UserID: tc.userID})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected %v, got %v", tc.wantErr, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRemoveReviewer(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectPRMeta(mock, StatusOpen, "u1", "u2")
+	expectRolePolicy(mock, "pr1", "", 0)
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "u2").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "u2", EventUnassigned, ReasonReviewerRemoved)
+	expectFetchPR(mock, "u1")
+	mock.ExpectCommit()
+
+	pr, err := store.RemoveReviewer(context.Background(), ReviewerPayload{PRID: "pr1", UserID: "u2"})
+	if err != nil {
+		t.Fatalf("RemoveReviewer error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 1 {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRemoveReviewerKeepsPolicy(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectPRMeta(mock, StatusOpen, "senior", "u2")
+	expectRolePolicy(mock, "pr1", RoleSenior, 1)
+	mock.ExpectQuery(`SELECT ar.user_id, u.role`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "role"}).AddRow("senior", RoleSenior).AddRow("u2", RoleMember))
+	mock.ExpectRollback()
+
+	_, err := store.RemoveReviewer(context.Background(), ReviewerPayload{PRID: "pr1", UserID: "senior"})
+	if !errors.Is(err, ErrPolicyUnsatisfiable) {
+		t.Fatalf("expected ErrPolicyUnsatisfiable, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	expectPRMeta(mock, StatusOpen, "u2")
+	mock.ExpectRollback()
+	_, err = store.RemoveReviewer(context.Background(), ReviewerPayload{PRID: "pr1", UserID: "u9"})
+	if !errors.Is(err, ErrNotAssigned) {
+		t.Fatalf("expected ErrNotAssigned, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Reviewer roles within a team, ordered by seniority: a policy asking for a
+// role is satisfied by that role or any higher one.
+const (
+	RoleMember = "member"
+	RoleSenior = "senior"
+	RoleLead   = "lead"
+)
+
+var roleRanks = map[string]int{RoleMember: 0, RoleSenior: 1, RoleLead: 2}
+
+// IsKnownRole reports whether role is one of the reviewer roles.
+func IsKnownRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// roleAtLeast reports whether role ranks at or above required.
+func roleAtLeast(role, required string) bool {
+	return roleRanks[role] >= roleRanks[required]
+}
+
+// normalizeRole maps an empty role to RoleMember and rejects unknown roles.
+func normalizeRole(role string) (string, error) {
+	if role == "" {
+		return RoleMember, nil
+	}
+	if !IsKnownRole(role) {
+		return "", ErrInvalidRole
+	}
+	return role, nil
+}
+
+// validateRolePolicy checks a team policy requiring count reviewers of at
+// least role; a zero count disables the policy.
+func validateRolePolicy(role string, count, maxReviewers int) error {
+	if count < 0 || count > maxReviewers {
+		return ErrInvalidPolicy
+	}
+	if count > 0 && (role == "" || role == RoleMember || !IsKnownRole(role)) {
+		return ErrInvalidPolicy
+	}
+	return nil
+}
+
+// SetRolePayload changes the reviewer role of a user; an empty role means RoleMember.
+type SetRolePayload struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// SetUserRole changes the role of a user. Reviews already assigned are kept even
+// when they no longer satisfy the team policy.
+func (s *Store) SetUserRole(ctx context.Context, payload SetRolePayload) (*User, error) {
+	role, err := normalizeRole(payload.Role)
+	if err != nil {
+		return nil, err
+	}
+	row := s.db.QueryRowContext(ctx, `
+UPDATE users
+SET role = $2
+WHERE user_id = $1
+RETURNING user_id, username, team_name, is_active, COALESCE(max_open_reviews, 0), role
+`, payload.UserID, role)
+	var u User
+	var team sql.NullString
+	if err := row.Scan(&u.ID, &u.Username, &team, &u.IsActive, &u.MaxOpenReviews, &u.Role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	u.TeamName = team.String
+	return &u, nil
+}
+
+// roleHolders returns the candidates whose role satisfies required.
+func roleHolders(candidates []Candidate, required string) []Candidate {
+	out := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if roleAtLeast(c.Role, required) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// reassignPolicy returns the rules a replacement for oldUserID must follow:
+// when the old reviewer counts towards the policy of the author's team and
+// the remaining reviewers no longer satisfy it, the replacement must hold the
+// required role as well.
+func reassignPolicy(ctx context.Context, tx *sql.Tx, prID, oldUserID string) (selectionRules, error) {
+	var rules selectionRules
+	if err := tx.QueryRowContext(ctx, `
+SELECT COALESCE(t.required_role, ''), COALESCE(t.required_role_count, 0)
+FROM pull_requests pr
+JOIN users a ON a.user_id = pr.author_id
+JOIN teams t ON t.name = a.team_name
+WHERE pr.pr_id=$1
+`, prID).Scan(&rules.role, &rules.roleCount); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return selectionRules{}, nil
+		}
+		return selectionRules{}, err
+	}
+	if rules.roleCount == 0 {
+		return selectionRules{}, nil
+	}
+	rows, err := tx.QueryContext(ctx, `
+SELECT ar.user_id, u.role
+FROM assigned_reviewers ar
+JOIN users u ON u.user_id = ar.user_id
+WHERE ar.pr_id=$1
+`, prID)
+	if err != nil {
+		return selectionRules{}, err
+	}
+	defer func() { _ = rows.Close() }()
+	oldCounts, remaining := false, 0
+	for rows.Next() {
+		var userID, role string
+		if err := rows.Scan(&userID, &role); err != nil {
+			return selectionRules{}, err
+		}
+		if !roleAtLeast(role, rules.role) {
+			continue
+		}
+		if userID == oldUserID {
+			oldCounts = true
+		} else {
+			remaining++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return selectionRules{}, err
+	}
+	if !oldCounts || remaining >= rules.roleCount {
+		return selectionRules{}, nil
+	}
+	rules.roleCount = 1
+	return rules, nil
+}
@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectPolicyLookups registers the CreatePR lookups of a team requiring count reviewers of role.
+func expectPolicyLookups(mock sqlmock.Sqlmock, maxReviewers int, role string, count int) {
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM pull_requests WHERE pr_id=`).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`SELECT user_id, team_name FROM users WHERE user_id=`).
+		WithArgs(authorID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_name"}).AddRow(authorID, teamBackend))
+	mock.ExpectQuery(teamSettingsPattern).
+		WithArgs(teamBackend).
+		WillReturnRows(sqlmock.NewRows([]string{"reviewer_strategy", "min_reviewers", "max_reviewers", "default_max_open_reviews", "pairing_window", "required_role", "required_role_count", "required_approvals"}).
+			AddRow(StrategyRoundRobin, 1, maxReviewers, 0, 0, role, count, 0))
+}
+
+func TestValidateRolePolicy(t *testing.T) {
+	cases := []struct {
+		role  string
+		count int
+		ok    bool
+	}{
+		{"", 0, true},
+		{RoleSenior, 1, true},
+		{RoleLead, 2, true},
+		{RoleSenior, 3, false},
+		{RoleSenior, -1, false},
+		{RoleMember, 1, false},
+		{"", 1, false},
+		{"boss", 1, false},
+	}
+	for _, tc := range cases {
+		err := validateRolePolicy(tc.role, tc.count, 2)
+		if tc.ok != (err == nil) {
+			t.Fatalf("validateRolePolicy(%q, %d) = %v", tc.role, tc.count, err)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidPolicy) {
+			t.Fatalf("expected ErrInvalidPolicy, got %v", err)
+		}
+	}
+}
+
+func TestCreatePRPicksRoleHoldersFirst(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	assignedAt := time.Now()
+	mock.ExpectBegin()
+	expectPolicyLookups(mock, 2, RoleSenior, 1)
+	mock.ExpectQuery(membersPattern).WithArgs(teamBackend, StatusOpen).
+		WillReturnRows(sqlmock.NewRows(memberColumns).
+			AddRow("u1", true, 1, 0, false, nil, 0, RoleMember).
+			AddRow("u2", true, 1, 0, false, nil, 0, RoleMember).
+			AddRow("u3", true, 1, 0, false, assignedAt, 0, RoleLead))
+	mock.ExpectExec(`INSERT INTO pull_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	for i := 0; i < 2; i++ {
+		mock.ExpectExec(`INSERT INTO assigned_reviewers`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO assignment_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	pr, err := store.CreatePR(context.Background(), CreatePRPayload{
+		ID: "pr1", Name: "feature", Author: authorID, Explain: true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePR error: %v", err)
+	}
+	if want := []string{"u3", "u1"}; !reflect.DeepEqual(pr.AssignedReviewers, want) {
+		t.Fatalf("reviewers = %v, want %v", pr.AssignedReviewers, want)
+	}
+	if len(pr.Explanation) != 1 || !reflect.DeepEqual(pr.Explanation[0].PolicyReviewers, []string{"u3"}) {
+		t.Fatalf("unexpected explanation: %+v", pr.Explanation)
+	}
+}
+
+func TestCreatePRPolicyUnsatisfiable(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectPolicyLookups(mock, 2, RoleSenior, 1)
+	expectCandidateRows(mock, teamBackend, "u1", "u2")
+	expectFallbackTeams(mock, teamBackend)
+	mock.ExpectRollback()
+
+	_, err := store.CreatePR(context.Background(), CreatePRPayload{ID: "pr1", Name: "feature", Author: authorID})
+	if !errors.Is(err, ErrPolicyUnsatisfiable) {
+		t.Fatalf("expected ErrPolicyUnsatisfiable, got %v", err)
+	}
+}
+
+func TestReassignPolicyHolderRequiresRoleHolder(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT author_id, status FROM pull_requests`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "status"}).AddRow("author", StatusOpen))
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("old").AddRow("other"))
+	mock.ExpectQuery(`SELECT team_name FROM users`).WithArgs("old").
+		WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow(teamBackend))
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM assigned_reviewers`).WithArgs("pr1", "old").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	expectRolePolicy(mock, "pr1", RoleSenior, 1)
+	mock.ExpectQuery(`SELECT ar.user_id, u.role`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "role"}).AddRow("old", RoleSenior).AddRow("other", RoleMember))
+	expectTeamSettings(mock, teamBackend)
+	mock.ExpectQuery(membersPattern).WithArgs(teamBackend, StatusOpen).
+		WillReturnRows(sqlmock.NewRows(memberColumns).
+			AddRow("junior", true, 1, 0, false, nil, 0, RoleMember).
+			AddRow("senior", true, 1, 0, false, nil, 0, RoleSenior))
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "old").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "old", EventUnassigned, ReasonReassigned)
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "senior").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "senior", EventAssigned, ReasonReassigned)
+	mock.ExpectQuery(`SELECT pr_id, pr_name, author_id, status, created_at, merged_at FROM pull_requests`).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "pr_name", "author_id", "status", "created_at", "merged_at"}).
+			AddRow("pr1", "feature", "author", StatusOpen, time.Now(), nil))
+	mock.ExpectQuery(reviewsPattern).WithArgs("pr1").WillReturnRows(reviewRows("senior", "other"))
+	mock.ExpectCommit()
+
+	_, replaced, err := store.Reassign(context.Background(), ReassignPayload{PRID: "pr1", Old: "old"})
+	if err != nil {
+		t.Fatalf("Reassign error: %v", err)
+	}
+	if replaced != "senior" {
+		t.Fatalf("replaced = %s, want senior", replaced)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestDeactivatePolicyHolderRequiresRoleHolder(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	seniorOnly := func(prID string) {
+		expectRolePolicy(mock, prID, RoleSenior, 1)
+		mock.ExpectQuery(`SELECT ar.user_id, u.role`).WithArgs(prID).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "role"}).AddRow("old", RoleSenior).AddRow("other", RoleMember))
+		expectTeamSettings(mock, teamBackend)
+		mock.ExpectQuery(membersPattern).WithArgs(teamBackend, StatusOpen).
+			WillReturnRows(sqlmock.NewRows(memberColumns).AddRow("junior", true, 1, 0, false, nil, 0, RoleMember))
+		expectFallbackTeams(mock, teamBackend, "platform")
+		expectTeamSettings(mock, "platform")
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE users`).WithArgs("old", false).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "team_name", "is_active"}).
+			AddRow("old", "Old", teamBackend, false))
+	mock.ExpectQuery(`SELECT pr.pr_id, pr.author_id, ar.user_id`).WithArgs(StatusOpen, "old").
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "author_id", "user_id"}).
+			AddRow("pr1", authorID, "old").
+			AddRow("pr2", authorID, "old"))
+	// pr1: the only senior left is in the fallback team and takes over.
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers WHERE pr_id=`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("old").AddRow("other"))
+	seniorOnly("pr1")
+	mock.ExpectQuery(membersPattern).WithArgs("platform", StatusOpen).
+		WillReturnRows(sqlmock.NewRows(memberColumns).AddRow("psenior", true, 1, 0, false, nil, 0, RoleSenior))
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "old").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "old", EventUnassigned, ReasonUserDeactivated)
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "psenior").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "psenior", EventAssigned, ReasonUserDeactivated)
+	// pr2: nobody holds the role, so the junior is not put in the senior's place.
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers WHERE pr_id=`).WithArgs("pr2").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("old").AddRow("other"))
+	seniorOnly("pr2")
+	mock.ExpectQuery(membersPattern).WithArgs("platform", StatusOpen).
+		WillReturnRows(sqlmock.NewRows(memberColumns).AddRow("pjunior", true, 1, 0, false, nil, 0, RoleMember))
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr2", "old").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr2", "old", EventUnassigned, ReasonUserDeactivated)
+	mock.ExpectCommit()
+
+	_, summary, err := store.SetUserActive(context.Background(), SetActivePayload{UserID: "old", IsActive: false})
+	if err != nil {
+		t.Fatalf("SetUserActive error: %v", err)
+	}
+	want := []Reassignment{{PRID: "pr1", OldUserID: "old", NewUserID: "psenior"}}
+	if !reflect.DeepEqual(summary.Reassigned, want) || !reflect.DeepEqual(summary.Unfilled, []string{"pr2"}) {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestUpdateTeamSettingsRejectsInvalidPolicy(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(teamSettingsPattern + `\s+WHERE name=\$1 FOR UPDATE`).
+		WithArgs(teamBackend).
+		WillReturnRows(teamSettingsRows(nil, DefaultMinReviewers, DefaultMaxReviewers))
+	mock.ExpectRollback()
+
+	role, count := RoleSenior, 3
+	_, err := store.UpdateTeamSettings(context.Background(), TeamSettingsPayload{
+		TeamName: teamBackend, RequiredRole: &role, RequiredRoleCount: &count,
+	})
+	if !errors.Is(err, ErrInvalidPolicy) {
+		t.Fatalf("expected ErrInvalidPolicy, got %v", err)
+	}
+}
+
+func TestSetUserRole(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`UPDATE users\s+SET role = \$2`).
+		WithArgs("u1", RoleLead).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "team_name", "is_active", "max_open_reviews", "role"}).
+			AddRow("u1", "Alice", teamBackend, true, 0, RoleLead))
+
+	u, err := store.SetUserRole(context.Background(), SetRolePayload{UserID: "u1", Role: RoleLead})
+	if err != nil {
+		t.Fatalf("SetUserRole error: %v", err)
+	}
+	if u.Role != RoleLead {
+		t.Fatalf("unexpected user: %+v", u)
+	}
+
+	if _, err := store.SetUserRole(context.Background(), SetRolePayload{UserID: "u1", Role: "boss"}); !errors.Is(err, ErrInvalidRole) {
+		t.Fatalf("expected ErrInvalidRole, got %v", err)
+	}
+}
+
+func TestAddTeamRejectsUnknownRole(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO teams`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	_, err := store.AddTeam(context.Background(), TeamPayload{
+		TeamName: teamBackend,
+		Members:  []TeamUpserted{{UserID: "u1", Username: "Alice", IsActive: true, Role: "boss"}},
+	})
+	if !errors.Is(err, ErrInvalidRole) {
+		t.Fatalf("expected ErrInvalidRole, got %v", err)
+	}
+}
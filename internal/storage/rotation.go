@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"sort"
+)
+
+// loadRecentPairings counts how many of the author's last window pull requests
+// each reviewer was assigned to.
+func loadRecentPairings(ctx context.Context, q querier, authorID string, window int) (map[string]int, error) {
+	rows, err := q.QueryContext(ctx, `
+SELECT ar.user_id, COUNT(*)
+FROM assigned_reviewers ar
+JOIN (
+    SELECT pr_id FROM pull_requests
+    WHERE author_id=$1
+    ORDER BY created_at DESC, pr_id DESC
+    LIMIT $2
+) recent ON recent.pr_id = ar.pr_id
+GROUP BY ar.user_id`, authorID, window)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	pairings := make(map[string]int)
+	for rows.Next() {
+		var userID string
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, err
+		}
+		pairings[userID] = count
+	}
+	return pairings, rows.Err()
+}
+
+// selectWithRotation lets the strategy pick among the candidates paired with
+// the author least often first and only moves on to more frequent pairings
+// when that tier cannot fill the limit.
+func selectWithRotation(sel ReviewerSelector, candidates []Candidate, limit int) []string {
+	tiers := make(map[int][]Candidate)
+	for _, c := range candidates {
+		tiers[c.RecentPairings] = append(tiers[c.RecentPairings], c)
+	}
+	counts := make([]int, 0, len(tiers))
+	for count := range tiers {
+		counts = append(counts, count)
+	}
+	sort.Ints(counts)
+
+	selected := make([]string, 0, min(limit, len(candidates)))
+	for _, count := range counts {
+		if len(selected) == limit {
+			break
+		}
+		selected = append(selected, sel.Select(tiers[count], limit-len(selected))...)
+	}
+	return selected
+}
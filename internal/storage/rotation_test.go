@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSelectWithRotationPrefersFreshPairings(t *testing.T) {
+	candidates := []Candidate{
+		{UserID: "u1", RecentPairings: 3},
+		{UserID: "u2", RecentPairings: 0},
+		{UserID: "u3", RecentPairings: 1},
+		{UserID: "u4", RecentPairings: 0},
+	}
+	got := selectWithRotation(roundRobinSelector{}, candidates, 3)
+	if want := []string{"u2", "u4", "u3"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("selected = %v, want %v", got, want)
+	}
+	got = selectWithRotation(roundRobinSelector{}, candidates, 10)
+	if len(got) != len(candidates) {
+		t.Fatalf("expected every candidate once, got %v", got)
+	}
+}
+
+func TestPickCandidatesAppliesPairingWindow(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectQuery(teamSettingsPattern).
+		WithArgs(teamBackend).
+		WillReturnRows(sqlmock.NewRows([]string{"reviewer_strategy", "min_reviewers", "max_reviewers", "default_max_open_reviews", "pairing_window", "required_role", "required_role_count", "required_approvals"}).
+			AddRow(StrategyRoundRobin, DefaultMinReviewers, DefaultMaxReviewers, 0, 5, "", 0, 0))
+	expectCandidateRows(mock, teamBackend, "u1", "u2", "u3")
+	mock.ExpectQuery(`SELECT ar.user_id, COUNT\(\*\)\s+FROM assigned_reviewers ar`).
+		WithArgs(authorID, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "count"}).AddRow("u1", 4).AddRow("u2", 1))
+
+	got, err := store.pickCandidates(context.Background(), store.db, teamBackend, authorID, nil, 2)
+	if err != nil {
+		t.Fatalf("pickCandidates error: %v", err)
+	}
+	if want := []string{"u3", "u2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("selected = %v, want %v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestUpdateTeamSettingsRejectsNegativePairingWindow(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(teamSettingsPattern + `\s+WHERE name=\$1 FOR UPDATE`).
+		WithArgs("platform").
+		WillReturnRows(teamSettingsRows(nil, 1, 2))
+	mock.ExpectRollback()
+
+	window := -1
+	_, err := store.UpdateTeamSettings(context.Background(), TeamSettingsPayload{TeamName: "platform", PairingWindow: &window})
+	if !errors.Is(err, ErrInvalidPairingWindow) {
+		t.Fatalf("expected ErrInvalidPairingWindow, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"sort"
+	"time"
+)
+
+// Reviewer selection strategies. A team may pin one of them, otherwise the
+// store-wide default is used.
+const (
+	StrategyRandom      = "random"
+	StrategyRoundRobin  = "round_robin"
+	StrategyWeighted    = "weighted"
+	StrategyLeastLoaded = "least_loaded"
+)
+
+const defaultReviewWeight = 1
+
+// Candidate is an eligible reviewer together with the data strategies rank on.
+type Candidate struct {
+	UserID         string
+	Weight         int
+	LastAssignedAt *time.Time
+	// OpenReviews counts current assignments on OPEN pull requests.
+	OpenReviews int
+	// MaxOpenReviews is the personal capacity, 0 when only the team default applies.
+	MaxOpenReviews int
+	// Role is the reviewer role within the team.
+	Role string
+	// RecentPairings counts reviews of the PR author's recent pull requests,
+	// filled only when the team has a pairing window.
+	RecentPairings int
+}
+
+// ReviewerSelector picks up to limit reviewers out of the eligible candidates.
+type ReviewerSelector interface {
+	Select(candidates []Candidate, limit int) []string
+}
+
+// IsKnownStrategy reports whether name refers to a registered selection strategy.
+func IsKnownStrategy(name string) bool {
+	switch name {
+	case StrategyRandom, StrategyRoundRobin, StrategyWeighted, StrategyLeastLoaded:
+		return true
+	}
+	return false
+}
+
+func newSelectors(rnd Random) map[string]ReviewerSelector {
+	return map[string]ReviewerSelector{
+		StrategyRandom:      randomSelector{rnd: rnd},
+		StrategyRoundRobin:  roundRobinSelector{},
+		StrategyWeighted:    weightedSelector{rnd: rnd},
+		StrategyLeastLoaded: leastLoadedSelector{rnd: rnd},
+	}
+}
+
+// randomSelector shuffles the pool and takes the head.
+type randomSelector struct {
+	rnd Random
+}
+
+func (r randomSelector) Select(candidates []Candidate, limit int) []string {
+	ids := candidateIDs(candidates)
+	r.rnd.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	return trimIDs(ids, limit)
+}
+
+// roundRobinSelector rotates through the team: whoever was assigned least
+// recently goes first, never-assigned members before everyone else.
+type roundRobinSelector struct{}
+
+func (roundRobinSelector) Select(candidates []Candidate, limit int) []string {
+	sorted := append([]Candidate(nil), candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].LastAssignedAt, sorted[j].LastAssignedAt
+		switch {
+		case a == nil && b == nil:
+			return sorted[i].UserID < sorted[j].UserID
+		case a == nil:
+			return true
+		case b == nil:
+			return false
+		case a.Equal(*b):
+			return sorted[i].UserID < sorted[j].UserID
+		default:
+			return a.Before(*b)
+		}
+	})
+	return trimIDs(candidateIDs(sorted), limit)
+}
+
+// weightedSelector draws reviewers without replacement with probability
+// proportional to their review weight.
+type weightedSelector struct {
+	rnd Random
+}
+
+func (w weightedSelector) Select(candidates []Candidate, limit int) []string {
+	pool := append([]Candidate(nil), candidates...)
+	out := make([]string, 0, min(limit, len(pool)))
+	for len(pool) > 0 && len(out) < limit {
+		total := 0
+		for _, c := range pool {
+			total += effectiveWeight(c)
+		}
+		pick := w.rnd.Intn(total)
+		for i, c := range pool {
+			pick -= effectiveWeight(c)
+			if pick < 0 {
+				out = append(out, c.UserID)
+				pool = append(pool[:i], pool[i+1:]...)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// leastLoadedSelector prefers members with the fewest open reviews; members
+// with equal load are ordered randomly so nobody wins ties systematically.
+type leastLoadedSelector struct {
+	rnd Random
+}
+
+func (l leastLoadedSelector) Select(candidates []Candidate, limit int) []string {
+	shuffled := append([]Candidate(nil), candidates...)
+	l.rnd.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	sort.SliceStable(shuffled, func(i, j int) bool {
+		return shuffled[i].OpenReviews < shuffled[j].OpenReviews
+	})
+	return trimIDs(candidateIDs(shuffled), limit)
+}
+
+func effectiveWeight(c Candidate) int {
+	if c.Weight <= 0 {
+		return defaultReviewWeight
+	}
+	return c.Weight
+}
+
+func candidateIDs(candidates []Candidate) []string {
+	ids := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.UserID)
+	}
+	return ids
+}
+
+func trimIDs(ids []string, limit int) []string {
+	if len(ids) > limit {
+		return ids[:limit]
+	}
+	return ids
+}
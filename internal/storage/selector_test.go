@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRoundRobinSelectorPrefersLeastRecentlyAssigned(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	candidates := []Candidate{
+		{UserID: "u1", LastAssignedAt: &now},
+		{UserID: "u2", LastAssignedAt: &earlier},
+		{UserID: "u3"},
+	}
+	got := roundRobinSelector{}.Select(candidates, 2)
+	if len(got) != 2 || got[0] != "u3" || got[1] != "u2" {
+		t.Fatalf("unexpected order: %v", got)
+	}
+}
+
+func TestWeightedSelectorNeverRepeats(t *testing.T) {
+	sel := weightedSelector{rnd: rand.New(rand.NewSource(1))} //nolint:gosec
+	candidates := []Candidate{
+		{UserID: "u1", Weight: 10},
+		{UserID: "u2", Weight: 1},
+		{UserID: "u3", Weight: 0},
+	}
+	got := sel.Select(candidates, 5)
+	if len(got) != 3 {
+		t.Fatalf("expected all 3 candidates, got %v", got)
+	}
+	seen := make(map[string]bool)
+	for _, id := range got {
+		if seen[id] {
+			t.Fatalf("duplicate candidate %s in %v", id, got)
+		}
+		seen[id] = true
+	}
+}
+
+func TestWeightedSelectorFavorsHeavierCandidates(t *testing.T) {
+	sel := weightedSelector{rnd: rand.New(rand.NewSource(42))} //nolint:gosec
+	candidates := []Candidate{
+		{UserID: "heavy", Weight: 9},
+		{UserID: "light", Weight: 1},
+	}
+	heavy := 0
+	for i := 0; i < 1000; i++ {
+		if sel.Select(candidates, 1)[0] == "heavy" {
+			heavy++
+		}
+	}
+	if heavy < 800 {
+		t.Fatalf("heavy picked %d/1000 times, expected ~900", heavy)
+	}
+}
+
+func TestIsKnownStrategy(t *testing.T) {
+	for _, name := range []string{StrategyRandom, StrategyRoundRobin, StrategyWeighted} {
+		if !IsKnownStrategy(name) {
+			t.Fatalf("expected %s to be known", name)
+		}
+	}
+	if IsKnownStrategy("nope") {
+		t.Fatal("unexpected known strategy")
+	}
+}
+
+func TestPickCandidatesUsesTeamStrategy(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	recent := time.Now()
+	mock.ExpectQuery(teamSettingsPattern).
+		WithArgs(teamBackend).
+		WillReturnRows(teamSettingsRows(StrategyRoundRobin, DefaultMinReviewers, DefaultMaxReviewers))
+	mock.ExpectQuery(membersPattern).WithArgs(teamBackend, StatusOpen).
+		WillReturnRows(sqlmock.NewRows(memberColumns).
+			AddRow("u1", true, 1, 0, false, recent, 0, RoleMember).
+			AddRow("u2", true, 1, 0, false, nil, 0, RoleMember))
+
+	cands, err := store.pickCandidates(context.Background(), store.db, teamBackend, authorID, nil, 1)
+	if err != nil {
+		t.Fatalf("pickCandidates error: %v", err)
+	}
+	if len(cands) != 1 || cands[0] != "u2" {
+		t.Fatalf("expected round robin to pick u2, got %v", cands)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestPickCandidatesTeamNotFound(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectQuery(teamSettingsPattern).
+		WithArgs("ghost").
+		WillReturnRows(sqlmock.NewRows([]string{"reviewer_strategy", "min_reviewers", "max_reviewers", "default_max_open_reviews", "pairing_window", "required_role", "required_role_count", "required_approvals"}))
+
+	if _, err := store.pickCandidates(context.Background(), store.db, "ghost", "", nil, 2); !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+}
+
+func TestLeastLoadedSelectorPicksLowestLoad(t *testing.T) {
+	sel := leastLoadedSelector{rnd: rand.New(rand.NewSource(7))} //nolint:gosec
+	candidates := []Candidate{
+		{UserID: "busy", OpenReviews: 5},
+		{UserID: "idle", OpenReviews: 0},
+		{UserID: "some", OpenReviews: 2},
+	}
+	got := sel.Select(candidates, 2)
+	if len(got) != 2 || got[0] != "idle" || got[1] != "some" {
+		t.Fatalf("unexpected selection: %v", got)
+	}
+}
+
+func TestLeastLoadedSelectorBreaksTiesRandomly(t *testing.T) {
+	sel := leastLoadedSelector{rnd: rand.New(rand.NewSource(3))} //nolint:gosec
+	candidates := []Candidate{
+		{UserID: "a", OpenReviews: 1},
+		{UserID: "b", OpenReviews: 1},
+		{UserID: "c", OpenReviews: 4},
+	}
+	seen := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		got := sel.Select(candidates, 1)
+		seen[got[0]]++
+	}
+	if seen["c"] != 0 {
+		t.Fatalf("loaded candidate picked: %v", seen)
+	}
+	if seen["a"] == 0 || seen["b"] == 0 {
+		t.Fatalf("ties not broken randomly: %v", seen)
+	}
+}
+
+func TestPickCandidatesLeastLoadedUsesOpenReviews(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectQuery(teamSettingsPattern).
+		WithArgs(teamBackend).
+		WillReturnRows(teamSettingsRows(StrategyLeastLoaded, DefaultMinReviewers, DefaultMaxReviewers))
+	mock.ExpectQuery(`LEFT JOIN pull_requests pr ON pr.pr_id = ar.pr_id AND pr.status = \$2`).
+		WithArgs(teamBackend, StatusOpen).
+		WillReturnRows(sqlmock.NewRows(memberColumns).
+			AddRow("senior", true, 1, 0, false, nil, 7, RoleMember).
+			AddRow("junior", true, 1, 0, false, nil, 0, RoleMember).
+			AddRow("middle", true, 1, 0, false, nil, 1, RoleMember))
+
+	cands, err := store.pickCandidates(context.Background(), store.db, teamBackend, authorID, nil, 2)
+	if err != nil {
+		t.Fatalf("pickCandidates error: %v", err)
+	}
+	if len(cands) != 2 || cands[0] != "junior" || cands[1] != "middle" {
+		t.Fatalf("unexpected candidates: %v", cands)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSeededRandomIsReproducible(t *testing.T) {
+	candidates := []Candidate{{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}, {UserID: "u4"}, {UserID: "u5"}}
+	first := newSelectors(NewSeededRandom(99))
+	second := newSelectors(NewSeededRandom(99))
+	for _, strategy := range []string{StrategyRandom, StrategyWeighted, StrategyLeastLoaded} {
+		for i := 0; i < 10; i++ {
+			a := first[strategy].Select(candidates, 2)
+			b := second[strategy].Select(candidates, 2)
+			if !reflect.DeepEqual(a, b) {
+				t.Fatalf("%s draw %d differs: %v vs %v", strategy, i, a, b)
+			}
+		}
+	}
+}
+
+func TestSeedAndRandomOptionsLastWins(t *testing.T) {
+	custom := NewSeededRandom(1)
+	store := NewStore(nil, nil, WithSeed(5), WithRandom(custom))
+	if store.rnd != custom {
+		t.Fatalf("WithRandom after WithSeed should win")
+	}
+	store = NewStore(nil, nil, WithRandom(custom), WithSeed(5))
+	if store.rnd == custom || store.seed != 5 {
+		t.Fatalf("WithSeed after WithRandom should win")
+	}
+	store = NewStore(nil, nil, WithSeed(5), WithRandom(nil))
+	if store.rnd == nil || store.seed != 5 {
+		t.Fatalf("WithRandom(nil) should keep the seed")
+	}
+}
+
+func TestSeededRandomConcurrentUse(t *testing.T) {
+	sel := randomSelector{rnd: NewSeededRandom(1)}
+	candidates := []Candidate{{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := sel.Select(candidates, 2); len(got) != 2 {
+					t.Errorf("unexpected selection: %v", got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Default reviewer bounds of a team. PRs get MaxReviewers reviewers unless the
+// author asks for a different count within [MinReviewers, MaxReviewers].
+const (
+	DefaultMinReviewers = 1
+	DefaultMaxReviewers = 2
+)
+
+type TeamSettings struct {
+	TeamName         string `json:"team_name"`
+	ReviewerStrategy string `json:"reviewer_strategy,omitempty"`
+	MinReviewers     int    `json:"min_reviewers"`
+	MaxReviewers     int    `json:"max_reviewers"`
+	// DefaultMaxOpenReviews is the capacity of members without a personal limit, 0 means no cap.
+	DefaultMaxOpenReviews int      `json:"default_max_open_reviews,omitempty"`
+	FallbackTeams         []string `json:"fallback_teams"`
+	// RequiredRoleCount reviewers of every PR must hold RequiredRole or a higher role.
+	RequiredRole      string `json:"required_role,omitempty"`
+	RequiredRoleCount int    `json:"required_role_count,omitempty"`
+	// PairingWindow is how many of the author's latest PRs rotation looks back at, 0 disables it.
+	PairingWindow int `json:"pairing_window,omitempty"`
+	// RequiredApprovals is how many APPROVED verdicts a PR needs before it can be merged.
+	RequiredApprovals int `json:"required_approvals,omitempty"`
+}
+
+// TeamSettingsPayload is a partial update: nil fields keep their current value,
+// an empty reviewer_strategy resets the team to the configured default.
+type TeamSettingsPayload struct {
+	TeamName         string  `json:"team_name"`
+	ReviewerStrategy *string `json:"reviewer_strategy,omitempty"`
+	MinReviewers     *int    `json:"min_reviewers,omitempty"`
+	MaxReviewers     *int    `json:"max_reviewers,omitempty"`
+	// DefaultMaxOpenReviews of 0 removes the team cap.
+	DefaultMaxOpenReviews *int `json:"default_max_open_reviews,omitempty"`
+	// FallbackTeams replaces the ordered fallback list when present; [] clears it.
+	FallbackTeams []string `json:"fallback_teams,omitempty"`
+	// RequiredRoleCount of 0 removes the role policy.
+	RequiredRole      *string `json:"required_role,omitempty"`
+	RequiredRoleCount *int    `json:"required_role_count,omitempty"`
+	// PairingWindow of 0 turns rotation memory off.
+	PairingWindow *int `json:"pairing_window,omitempty"`
+	// RequiredApprovals of 0 lets PRs merge without approvals.
+	RequiredApprovals *int `json:"required_approvals,omitempty"`
+}
+
+func (s *Store) UpdateTeamSettings(ctx context.Context, payload TeamSettingsPayload) (*TeamSettings, error) {
+	for attempts := 0; attempts < 3; attempts++ {
+		settings, err := s.updateTeamSettingsOnce(ctx, payload)
+		if err == nil {
+			return settings, nil
+		}
+		if isRetryable(err) && attempts < 2 {
+			time.Sleep(time.Duration(attempts+1) * 10 * time.Millisecond)
+			continue
+		}
+		return nil, err
+	}
+	return nil, fmt.Errorf("unreachable")
+}
+
+func (s *Store) updateTeamSettingsOnce(ctx context.Context, payload TeamSettingsPayload) (*TeamSettings, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	settings, err := loadTeamSettingsForUpdate(ctx, tx, payload.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	if payload.ReviewerStrategy != nil {
+		settings.ReviewerStrategy = *payload.ReviewerStrategy
+	}
+	if payload.MinReviewers != nil {
+		settings.MinReviewers = *payload.MinReviewers
+	}
+	if payload.MaxReviewers != nil {
+		settings.MaxReviewers = *payload.MaxReviewers
+	}
+	if payload.DefaultMaxOpenReviews != nil {
+		settings.DefaultMaxOpenReviews = *payload.DefaultMaxOpenReviews
+	}
+	if payload.PairingWindow != nil {
+		settings.PairingWindow = *payload.PairingWindow
+	}
+	if payload.RequiredRole != nil {
+		settings.RequiredRole = *payload.RequiredRole
+	}
+	if payload.RequiredRoleCount != nil {
+		settings.RequiredRoleCount = *payload.RequiredRoleCount
+	}
+	if payload.RequiredApprovals != nil {
+		settings.RequiredApprovals = *payload.RequiredApprovals
+	}
+	if settings.RequiredRoleCount == 0 {
+		settings.RequiredRole = ""
+	}
+	if err := validateReviewerBounds(settings.MinReviewers, settings.MaxReviewers); err != nil {
+		return nil, err
+	}
+	if settings.DefaultMaxOpenReviews < 0 {
+		return nil, ErrInvalidCapacity
+	}
+	if settings.PairingWindow < 0 {
+		return nil, ErrInvalidPairingWindow
+	}
+	if err := validateRolePolicy(settings.RequiredRole, settings.RequiredRoleCount, settings.MaxReviewers); err != nil {
+		return nil, err
+	}
+	if settings.RequiredApprovals < 0 || settings.RequiredApprovals > settings.MaxReviewers {
+		return nil, ErrInvalidApprovals
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE teams
+SET reviewer_strategy = NULLIF($2, ''),
+    min_reviewers = $3,
+    max_reviewers = $4,
+    default_max_open_reviews = NULLIF($5, 0),
+    pairing_window = NULLIF($6, 0),
+    required_role = NULLIF($7, ''),
+    required_role_count = NULLIF($8, 0),
+    required_approvals = NULLIF($9, 0)
+WHERE name = $1
+`, settings.TeamName, settings.ReviewerStrategy, settings.MinReviewers, settings.MaxReviewers,
+		settings.DefaultMaxOpenReviews, settings.PairingWindow,
+		settings.RequiredRole, settings.RequiredRoleCount, settings.RequiredApprovals); err != nil {
+		return nil, err
+	}
+	if payload.FallbackTeams != nil {
+		if err := replaceFallbackTeams(ctx, tx, settings.TeamName, payload.FallbackTeams); err != nil {
+			return nil, err
+		}
+		settings.FallbackTeams = payload.FallbackTeams
+	} else {
+		settings.FallbackTeams, err = loadFallbackTeams(ctx, tx, settings.TeamName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func validateReviewerBounds(minReviewers, maxReviewers int) error {
+	if minReviewers < 1 || maxReviewers < 1 || minReviewers > maxReviewers {
+		return ErrInvalidReviewerCount
+	}
+	return nil
+}
+
+const teamSettingsQuery = `
+SELECT reviewer_strategy, min_reviewers, max_reviewers, COALESCE(default_max_open_reviews, 0),
+       COALESCE(pairing_window, 0), COALESCE(required_role, ''), COALESCE(required_role_count, 0),
+       COALESCE(required_approvals, 0)
+FROM teams
+WHERE name=$1`
+
+func loadTeamSettings(ctx context.Context, q querier, teamName string) (TeamSettings, error) {
+	return scanTeamSettings(q.QueryRowContext(ctx, teamSettingsQuery, teamName), teamName)
+}
+
+func loadTeamSettingsForUpdate(ctx context.Context, tx *sql.Tx, teamName string) (TeamSettings, error) {
+	return scanTeamSettings(tx.QueryRowContext(ctx, teamSettingsQuery+` FOR UPDATE`, teamName), teamName)
+}
+
+func scanTeamSettings(row *sql.Row, teamName string) (TeamSettings, error) {
+	settings := TeamSettings{TeamName: teamName}
+	var strategy sql.NullString
+	err := row.Scan(&strategy, &settings.MinReviewers, &settings.MaxReviewers,
+		&settings.DefaultMaxOpenReviews, &settings.PairingWindow, &settings.RequiredRole, &settings.RequiredRoleCount,
+		&settings.RequiredApprovals)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return TeamSettings{}, ErrTeamNotFound
+		}
+		return TeamSettings{}, err
+	}
+	settings.ReviewerStrategy = strategy.String
+	return settings, nil
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestUpdateTeamSettingsPartial(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(teamSettingsPattern + `\s+WHERE name=\$1 FOR UPDATE`).
+		WithArgs("platform").
+		WillReturnRows(teamSettingsRows(StrategyLeastLoaded, 1, 2))
+	mock.ExpectExec(`UPDATE teams`).
+		WithArgs("platform", StrategyLeastLoaded, 3, 3, 0, 0, "", 0, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectFallbackTeams(mock, "platform")
+	mock.ExpectCommit()
+
+	minReviewers, maxReviewers := 3, 3
+	settings, err := store.UpdateTeamSettings(context.Background(), TeamSettingsPayload{
+		TeamName:     "platform",
+		MinReviewers: &minReviewers,
+		MaxReviewers: &maxReviewers,
+	})
+	if err != nil {
+		t.Fatalf("UpdateTeamSettings error: %v", err)
+	}
+	if settings.ReviewerStrategy != StrategyLeastLoaded || settings.MinReviewers != 3 || settings.MaxReviewers != 3 {
+		t.Fatalf("unexpected settings: %+v", settings)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestUpdateTeamSettingsRejectsInvertedBounds(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(teamSettingsPattern).
+		WithArgs("docs").
+		WillReturnRows(teamSettingsRows(nil, 1, 2))
+	mock.ExpectRollback()
+
+	minReviewers := 3
+	_, err := store.UpdateTeamSettings(context.Background(), TeamSettingsPayload{
+		TeamName:     "docs",
+		MinReviewers: &minReviewers,
+	})
+	if !errors.Is(err, ErrInvalidReviewerCount) {
+		t.Fatalf("expected ErrInvalidReviewerCount, got %v", err)
+	}
+}
+
+func TestUpdateTeamSettingsTeamNotFound(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(teamSettingsPattern).
+		WithArgs("ghost").
+		WillReturnRows(sqlmock.NewRows([]string{"reviewer_strategy", "min_reviewers", "max_reviewers", "default_max_open_reviews", "pairing_window", "required_role", "required_role_count", "required_approvals"}))
+	mock.ExpectRollback()
+
+	_, err := store.UpdateTeamSettings(context.Background(), TeamSettingsPayload{TeamName: "ghost"})
+	if !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+}
+
+func TestAddTeamRejectsInvalidBounds(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	maxReviewers := 0
+	_, err := store.AddTeam(context.Background(), TeamPayload{TeamName: "docs", MaxReviewers: &maxReviewers})
+	if !errors.Is(err, ErrInvalidReviewerCount) {
+		t.Fatalf("expected ErrInvalidReviewerCount, got %v", err)
+	}
+}
+
+func TestAddTeamRejectsZeroMinReviewers(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	minReviewers := 0
+	_, err := store.AddTeam(context.Background(), TeamPayload{TeamName: "docs", MinReviewers: &minReviewers})
+	if !errors.Is(err, ErrInvalidReviewerCount) {
+		t.Fatalf("expected ErrInvalidReviewerCount, got %v", err)
+	}
+}
+
+func expectCreatePRLookups(mock sqlmock.Sqlmock, minReviewers, maxReviewers int) {
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM pull_requests WHERE pr_id=`).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`SELECT user_id, team_name FROM users WHERE user_id=`).
+		WithArgs(authorID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_name"}).AddRow(authorID, teamBackend))
+	mock.ExpectQuery(teamSettingsPattern).
+		WithArgs(teamBackend).
+		WillReturnRows(teamSettingsRows(nil, minReviewers, maxReviewers))
+}
+
+func TestCreatePRUsesTeamMaxReviewers(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 3, 3)
+	expectCandidateRows(mock, teamBackend, "u1", "u2", "u3", "u4")
+	mock.ExpectExec(`INSERT INTO pull_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	for i := 0; i < 3; i++ {
+		mock.ExpectExec(`INSERT INTO assigned_reviewers`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO assignment_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	pr, err := store.CreatePR(context.Background(), CreatePRPayload{ID: "pr1", Name: "feature", Author: authorID})
+	if err != nil {
+		t.Fatalf("CreatePR error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 3 {
+		t.Fatalf("expected 3 reviewers, got %v", pr.AssignedReviewers)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCreatePRReportsMissingReviewers(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 3, 3)
+	expectCandidateRows(mock, teamBackend, "u1")
+	expectFallbackTeams(mock, teamBackend)
+	mock.ExpectExec(`INSERT INTO pull_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assignment_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	pr, err := store.CreatePR(context.Background(), CreatePRPayload{ID: "pr1", Name: "feature", Author: authorID})
+	if err != nil {
+		t.Fatalf("CreatePR error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 1 || pr.MissingReviewers != 2 {
+		t.Fatalf("expected 1 reviewer and 2 missing, got %v, %d", pr.AssignedReviewers, pr.MissingReviewers)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCreatePROverrideWithinBounds(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 1, 3)
+	expectCandidateRows(mock, teamBackend, "u1", "u2", "u3")
+	mock.ExpectExec(`INSERT INTO pull_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assignment_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	count := 1
+	pr, err := store.CreatePR(context.Background(), CreatePRPayload{
+		ID:             "pr1",
+		Name:           "docs",
+		Author:         authorID,
+		ReviewersCount: &count,
+	})
+	if err != nil {
+		t.Fatalf("CreatePR error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 1 {
+		t.Fatalf("expected 1 reviewer, got %v", pr.AssignedReviewers)
+	}
+}
+
+func TestCreatePROverrideOutOfBounds(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 2, 3)
+	mock.ExpectRollback()
+
+	count := 1
+	_, err := store.CreatePR(context.Background(), CreatePRPayload{
+		ID:             "pr1",
+		Name:           "feature",
+		Author:         authorID,
+		ReviewersCount: &count,
+	})
+	if !errors.Is(err, ErrInvalidReviewerCount) {
+		t.Fatalf("expected ErrInvalidReviewerCount, got %v", err)
+	}
+}
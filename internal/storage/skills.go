@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+)
+
+// selectionRules are PR-specific constraints applied on top of the team strategy.
+type selectionRules struct {
+	// requiredTags should each be covered by at least one selected reviewer.
+	requiredTags []string
+	// requireTags turns uncovered tags into ErrTagsUncovered instead of a warning.
+	requireTags bool
+	// roleCount reviewers must hold role or a higher one.
+	role      string
+	roleCount int
+	// excluded users are never picked, whatever team they belong to.
+	excluded map[string]struct{}
+}
+
+// normalizeTags lower-cases, trims and de-duplicates tags keeping their order.
+func normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, ErrInvalidTag
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+	return out, nil
+}
+
+func replaceUserSkills(ctx context.Context, tx *sql.Tx, userID string, skills []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_skills WHERE user_id=$1`, userID); err != nil {
+		return err
+	}
+	for _, skill := range skills {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO user_skills(user_id, skill) VALUES ($1, $2)`, userID, skill); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTeamSkills returns the skills of every team member that has any.
+func loadTeamSkills(ctx context.Context, q querier, teamName string) (map[string][]string, error) {
+	rows, err := q.QueryContext(ctx, `
+SELECT us.user_id, us.skill
+FROM user_skills us
+JOIN users u ON u.user_id = us.user_id
+WHERE u.team_name=$1
+ORDER BY us.user_id, us.skill
+`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	skills := make(map[string][]string)
+	for rows.Next() {
+		var userID, skill string
+		if err := rows.Scan(&userID, &skill); err != nil {
+			return nil, err
+		}
+		skills[userID] = append(skills[userID], skill)
+	}
+	return skills, rows.Err()
+}
+
+// loadUserSkills returns the skills of one user.
+func loadUserSkills(ctx context.Context, q querier, userID string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT skill FROM user_skills WHERE user_id=$1 ORDER BY skill`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var skills []string
+	for rows.Next() {
+		var skill string
+		if err := rows.Scan(&skill); err != nil {
+			return nil, err
+		}
+		skills = append(skills, skill)
+	}
+	return skills, rows.Err()
+}
+
+// coverTags picks, tag by tag, one candidate having the tag until every tag
+// is covered or limit is reached. choose applies the team strategy among the
+// candidates having the tag. It returns the picks and which pick covers each tag.
+func coverTags(
+	choose func([]Candidate, int) []string,
+	candidates []Candidate,
+	skills map[string][]string,
+	tags []string,
+	limit int,
+) ([]string, map[string]string) {
+	picked := make([]string, 0, min(limit, len(tags)))
+	covered := make(map[string]string, len(tags))
+	taken := make(map[string]struct{})
+	for _, tag := range tags {
+		if _, ok := covered[tag]; ok {
+			continue
+		}
+		if len(picked) == limit {
+			break
+		}
+		having := make([]Candidate, 0)
+		for _, c := range candidates {
+			if _, ok := taken[c.UserID]; ok {
+				continue
+			}
+			if slices.Contains(skills[c.UserID], tag) {
+				having = append(having, c)
+			}
+		}
+		chosen := choose(having, 1)
+		if len(chosen) == 0 {
+			continue
+		}
+		id := chosen[0]
+		picked = append(picked, id)
+		taken[id] = struct{}{}
+		for _, skill := range skills[id] {
+			if _, ok := covered[skill]; !ok && slices.Contains(tags, skill) {
+				covered[skill] = id
+			}
+		}
+	}
+	return picked, covered
+}
+
+// tagsCoveredBy maps each of tags held by one of the reviewers to the first such reviewer.
+func tagsCoveredBy(reviewers []string, skills map[string][]string, tags []string) map[string]string {
+	covered := make(map[string]string, len(tags))
+	for _, id := range reviewers {
+		for _, skill := range skills[id] {
+			if _, ok := covered[skill]; !ok && slices.Contains(tags, skill) {
+				covered[skill] = id
+			}
+		}
+	}
+	return covered
+}
+
+// uncoveredTags returns the tags with no entry in covered, keeping their order.
+func uncoveredTags(tags []string, covered map[string]string) []string {
+	var out []string
+	for _, tag := range tags {
+		if _, ok := covered[tag]; !ok {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
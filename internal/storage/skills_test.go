@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectSkillRows(mock sqlmock.Sqlmock, team string, pairs ...string) {
+	rows := sqlmock.NewRows([]string{"user_id", "skill"})
+	for i := 0; i+1 < len(pairs); i += 2 {
+		rows.AddRow(pairs[i], pairs[i+1])
+	}
+	mock.ExpectQuery(`SELECT us.user_id, us.skill`).WithArgs(team).WillReturnRows(rows)
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := normalizeTags([]string{" Go", "sql", "go", "FRONTEND "})
+	if err != nil {
+		t.Fatalf("normalizeTags error: %v", err)
+	}
+	if want := []string{"go", "sql", "frontend"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("tags = %v, want %v", got, want)
+	}
+	if _, err := normalizeTags([]string{"go", " "}); !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("expected ErrInvalidTag, got %v", err)
+	}
+}
+
+func TestCoverTagsPicksOneReviewerPerTag(t *testing.T) {
+	candidates := []Candidate{{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}}
+	skills := map[string][]string{
+		"u1": {"go"},
+		"u2": {"frontend", "go"},
+		"u3": {"sql"},
+	}
+	choose := roundRobinSelector{}.Select
+	picked, covered := coverTags(choose, candidates, skills, []string{"go", "frontend", "sql"}, 3)
+	if want := []string{"u1", "u2", "u3"}; !reflect.DeepEqual(picked, want) {
+		t.Fatalf("picked = %v, want %v", picked, want)
+	}
+	if covered["frontend"] != "u2" || covered["sql"] != "u3" {
+		t.Fatalf("unexpected coverage: %v", covered)
+	}
+
+	picked, covered = coverTags(choose, candidates, skills, []string{"frontend", "go", "rust"}, 2)
+	if want := []string{"u2"}; !reflect.DeepEqual(picked, want) {
+		t.Fatalf("picked = %v, want %v", picked, want)
+	}
+	if got := uncoveredTags([]string{"frontend", "go", "rust"}, covered); !reflect.DeepEqual(got, []string{"rust"}) {
+		t.Fatalf("uncovered = %v", got)
+	}
+}
+
+func TestCreatePRCoversRequiredTags(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 1, 1)
+	expectCandidateRows(mock, teamBackend, "u1", "u2", "u3")
+	expectSkillRows(mock, teamBackend, "u3", "frontend")
+	mock.ExpectExec(`INSERT INTO pull_requests`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "u3").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assignment_events`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	pr, err := store.CreatePR(context.Background(), CreatePRPayload{
+		ID: "pr1", Name: "feature", Author: authorID, RequiredTags: []string{"Frontend"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePR error: %v", err)
+	}
+	if !reflect.DeepEqual(pr.AssignedReviewers, []string{"u3"}) || len(pr.UncoveredTags) != 0 {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+}
+
+func TestCreatePRRequireTagsFailsWhenUncovered(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectCreatePRLookups(mock, 1, 2)
+	expectCandidateRows(mock, teamBackend, "u1", "u2")
+	expectSkillRows(mock, teamBackend, "u1", "go")
+	mock.ExpectRollback()
+
+	_, err := store.CreatePR(context.Background(), CreatePRPayload{
+		ID: "pr1", Name: "feature", Author: authorID, RequiredTags: []string{"go", "sql"}, RequireTags: true,
+	})
+	if !errors.Is(err, ErrTagsUncovered) {
+		t.Fatalf("expected ErrTagsUncovered, got %v", err)
+	}
+}
+
+func TestAddTeamStoresSkills(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO teams`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO users`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM user_skills WHERE user_id=`).WithArgs("u1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO user_skills`).WithArgs("u1", "go").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO user_skills`).WithArgs("u1", "sql").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectTeamSettings(mock, teamBackend)
+	mock.ExpectQuery(`SELECT u.user_id, u.username, u.is_active`).
+		WithArgs(teamBackend, StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "is_active", "review_weight", "max_open_reviews", "open_reviews", "role"}).
+			AddRow("u1", "Alice", true, 1, 0, 0, RoleMember))
+	expectSkillRows(mock, teamBackend, "u1", "go", "u1", "sql")
+	expectFallbackTeams(mock, teamBackend)
+	mock.ExpectCommit()
+
+	team, err := store.AddTeam(context.Background(), TeamPayload{
+		TeamName: teamBackend,
+		Members:  []TeamUpserted{{UserID: "u1", Username: "Alice", IsActive: true, Skills: []string{"Go", "sql"}}},
+	})
+	if err != nil {
+		t.Fatalf("AddTeam error: %v", err)
+	}
+	if !reflect.DeepEqual(team.Members[0].Skills, []string{"go", "sql"}) {
+		t.Fatalf("unexpected skills: %v", team.Members[0].Skills)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,367 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MembersPayload adds members to an existing team. Members are upserted the
+// way /team/add does it, but users of other teams are rejected: moving them
+// has to decide about their reviews, which is what MoveUserTeam is for.
+type MembersPayload struct {
+	TeamName string         `json:"team_name"`
+	Members  []TeamUpserted `json:"members"`
+}
+
+// RemoveMembersPayload lists members to remove from a team.
+type RemoveMembersPayload struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+}
+
+type RenameTeamPayload struct {
+	TeamName string `json:"team_name"`
+	NewName  string `json:"new_team_name"`
+}
+
+// RemovedMembers is the team after removal and the members removed from it.
+type RemovedMembers struct {
+	Team    TeamPayload     `json:"team"`
+	Removed []RemovedMember `json:"removed"`
+}
+
+// RemovedMember is a removed member, now without a team, and the reviews
+// handed over to the team they left.
+type RemovedMember struct {
+	User         *User                `json:"user"`
+	Reassignment *ReassignmentSummary `json:"reassignment"`
+}
+
+// TeamSummary is a team with aggregate counts of its members. OpenReviews is
+// the number of review assignments the members hold on OPEN PRs.
+type TeamSummary struct {
+	TeamName      string `json:"team_name"`
+	Members       int    `json:"members"`
+	ActiveMembers int    `json:"active_members"`
+	OpenReviews   int    `json:"open_reviews"`
+}
+
+// DeletedTeam names the deleted team and the ownership rules of other teams
+// that lost it as an owner.
+type DeletedTeam struct {
+	TeamName       string      `json:"team_name"`
+	OwnershipRules []OwnedRule `json:"ownership_rules"`
+}
+
+// ListTeams returns every team ordered by name, including teams without members.
+func (s *Store) ListTeams(ctx context.Context) ([]TeamSummary, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT t.name,
+       COUNT(u.user_id),
+       COUNT(u.user_id) FILTER (WHERE u.is_active),
+       COALESCE(SUM(l.open_reviews), 0)
+FROM teams t
+LEFT JOIN users u ON u.team_name = t.name
+LEFT JOIN (SELECT ar.user_id, COUNT(*) AS open_reviews
+           FROM assigned_reviewers ar
+           JOIN pull_requests pr ON pr.pr_id = ar.pr_id
+           WHERE pr.status = $1
+           GROUP BY ar.user_id) l ON l.user_id = u.user_id
+GROUP BY t.name
+ORDER BY t.name
+`, StatusOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	teams := make([]TeamSummary, 0)
+	for rows.Next() {
+		var t TeamSummary
+		if err := rows.Scan(&t.TeamName, &t.Members, &t.ActiveMembers, &t.OpenReviews); err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (s *Store) AddTeamMembers(ctx context.Context, payload MembersPayload) (TeamPayload, error) {
+	for attempts := 0; attempts < 3; attempts++ {
+		team, err := s.addTeamMembersOnce(ctx, payload)
+		if err == nil {
+			return team, nil
+		}
+		if isRetryable(err) && attempts < 2 {
+			time.Sleep(time.Duration(attempts+1) * 10 * time.Millisecond)
+			continue
+		}
+		return TeamPayload{}, err
+	}
+	return TeamPayload{}, fmt.Errorf("unreachable")
+}
+
+func (s *Store) addTeamMembersOnce(ctx context.Context, payload MembersPayload) (TeamPayload, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return TeamPayload{}, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	if err := lockTeam(ctx, tx, payload.TeamName); err != nil {
+		return TeamPayload{}, err
+	}
+	for _, m := range payload.Members {
+		if m.UserID == "" {
+			continue
+		}
+		var current sql.NullString
+		err := tx.QueryRowContext(ctx, `SELECT team_name FROM users WHERE user_id=$1`, m.UserID).Scan(&current)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return TeamPayload{}, err
+		}
+		if current.Valid && current.String != payload.TeamName {
+			return TeamPayload{}, fmt.Errorf("%w: %q is in team %q", ErrMemberOfOtherTeam, m.UserID, current.String)
+		}
+	}
+	if err := upsertTeamMembers(ctx, tx, payload.TeamName, payload.Members); err != nil {
+		return TeamPayload{}, err
+	}
+	team, err := buildTeam(ctx, tx, payload.TeamName)
+	if err != nil {
+		return TeamPayload{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return TeamPayload{}, err
+	}
+	return team, nil
+}
+
+// RemoveTeamMembers takes the listed members out of the team in one
+// transaction. They stay as users without a team, with their PRs and review
+// history, until /team/addMembers or MoveUserTeam puts them in a team again.
+// Their open reviews go to the remaining teammates. Members who author draft
+// or open PRs are refused: those PRs take reviewers from the author's team.
+func (s *Store) RemoveTeamMembers(ctx context.Context, payload RemoveMembersPayload) (*RemovedMembers, error) {
+	for attempts := 0; attempts < 3; attempts++ {
+		removed, err := s.removeTeamMembersOnce(ctx, payload)
+		if err == nil {
+			return removed, nil
+		}
+		if isSerializationError(err) && attempts < 2 {
+			time.Sleep(time.Duration(attempts+1) * 10 * time.Millisecond)
+			continue
+		}
+		return nil, err
+	}
+	return nil, fmt.Errorf("unreachable")
+}
+
+func (s *Store) removeTeamMembersOnce(ctx context.Context, payload RemoveMembersPayload) (*RemovedMembers, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	if err := lockTeam(ctx, tx, payload.TeamName); err != nil {
+		return nil, err
+	}
+	removed := make([]RemovedMember, 0, len(payload.UserIDs))
+	seen := make(map[string]struct{}, len(payload.UserIDs))
+	for _, userID := range payload.UserIDs {
+		if _, ok := seen[userID]; ok {
+			continue
+		}
+		seen[userID] = struct{}{}
+		member, err := s.removeMemberTx(ctx, tx, payload.TeamName, userID)
+		if err != nil {
+			return nil, err
+		}
+		removed = append(removed, member)
+	}
+	team, err := buildTeam(ctx, tx, payload.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &RemovedMembers{Team: team, Removed: removed}, nil
+}
+
+// removeMemberTx hands the open reviews of a member over to the team and
+// leaves the user without a team.
+func (s *Store) removeMemberTx(ctx context.Context, tx *sql.Tx, teamName, userID string) (RemovedMember, error) {
+	current, err := lockUser(ctx, tx, userID)
+	if err != nil {
+		return RemovedMember{}, err
+	}
+	if current != teamName {
+		return RemovedMember{}, fmt.Errorf("%w: %q is not in team %q", ErrNotTeamMember, userID, teamName)
+	}
+	authored, err := authoredPendingPRs(ctx, tx, userID)
+	if err != nil {
+		return RemovedMember{}, err
+	}
+	if len(authored) > 0 {
+		return RemovedMember{}, fmt.Errorf("%w: %q authors %s", ErrMemberHasOpenPRs, userID, strings.Join(authored, ", "))
+	}
+	assignments, err := s.fetchUserAssignments(ctx, tx, userID)
+	if err != nil {
+		return RemovedMember{}, err
+	}
+	summary, err := s.reassignAfterDeactivation(ctx, tx, teamName, assignments, ReasonUserRemoved)
+	if err != nil {
+		return RemovedMember{}, err
+	}
+	u, err := scanUser(tx.QueryRowContext(ctx, `
+UPDATE users
+SET team_name = NULL
+WHERE user_id = $1
+RETURNING `+userColumns, userID))
+	if err != nil {
+		return RemovedMember{}, err
+	}
+	return RemovedMember{User: u, Reassignment: summary}, nil
+}
+
+// RenameTeam changes the team name. Foreign keys cascade the new name to
+// members, fallback lists and ownership rules.
+func (s *Store) RenameTeam(ctx context.Context, payload RenameTeamPayload) (TeamPayload, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return TeamPayload{}, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, `UPDATE teams SET name = $2 WHERE name = $1`, payload.TeamName, payload.NewName)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return TeamPayload{}, ErrTeamExists
+		}
+		return TeamPayload{}, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return TeamPayload{}, err
+	}
+	if affected == 0 {
+		return TeamPayload{}, ErrTeamNotFound
+	}
+	team, err := buildTeam(ctx, tx, payload.NewName)
+	if err != nil {
+		return TeamPayload{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return TeamPayload{}, err
+	}
+	return team, nil
+}
+
+// DeleteTeam deletes an empty team with its settings and ownership rules.
+// Members have to be removed or moved first, so every open PR keeps its
+// author's team and no review history goes with the team.
+func (s *Store) DeleteTeam(ctx context.Context, teamName string) (*DeletedTeam, error) {
+	for attempts := 0; attempts < 3; attempts++ {
+		deleted, err := s.deleteTeamOnce(ctx, teamName)
+		if err == nil {
+			return deleted, nil
+		}
+		if isSerializationError(err) && attempts < 2 {
+			time.Sleep(time.Duration(attempts+1) * 10 * time.Millisecond)
+			continue
+		}
+		return nil, err
+	}
+	return nil, fmt.Errorf("unreachable")
+}
+
+func (s *Store) deleteTeamOnce(ctx context.Context, teamName string) (*DeletedTeam, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	if err := lockTeam(ctx, tx, teamName); err != nil {
+		return nil, err
+	}
+	members, err := teamMemberIDs(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTeamNotEmpty, strings.Join(members, ", "))
+	}
+	owned, err := ownedRules(ctx, tx, "owner_team", teamName)
+	if err != nil {
+		return nil, err
+	}
+	// The team's own rules are deleted with it.
+	owned = slices.DeleteFunc(owned, func(r OwnedRule) bool { return r.TeamName == teamName })
+	if _, err := tx.ExecContext(ctx, `DELETE FROM teams WHERE name=$1`, teamName); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &DeletedTeam{TeamName: teamName, OwnershipRules: owned}, nil
+}
+
+// lockTeam locks the team row so members cannot be changed concurrently.
+func lockTeam(ctx context.Context, tx *sql.Tx, teamName string) error {
+	var name string
+	if err := tx.QueryRowContext(ctx,
+		`SELECT name FROM teams WHERE name=$1 FOR UPDATE`, teamName).Scan(&name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTeamNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// teamMemberIDs returns the ids of the team's members.
+func teamMemberIDs(ctx context.Context, q querier, teamName string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT user_id FROM users WHERE team_name=$1 ORDER BY user_id`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,309 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectLockTeam(mock sqlmock.Sqlmock, team string) {
+	mock.ExpectQuery(`SELECT name FROM teams WHERE name=\$1 FOR UPDATE`).WithArgs(team).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow(team))
+}
+
+// expectBuildTeam registers the lookups of buildTeam for a team of active members.
+func expectBuildTeam(mock sqlmock.Sqlmock, team string, ids ...string) {
+	expectTeamSettings(mock, team)
+	rows := sqlmock.NewRows([]string{"user_id", "username", "is_active", "review_weight", "max_open_reviews", "open_reviews", "role"})
+	for _, id := range ids {
+		rows.AddRow(id, id, true, 1, 0, 0, RoleMember)
+	}
+	mock.ExpectQuery(`SELECT u.user_id, u.username, u.is_active`).WithArgs(team, StatusOpen).WillReturnRows(rows)
+	expectTeamSkills(mock, team)
+	expectFallbackTeams(mock, team)
+}
+
+func TestAddTeamMembers(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockTeam(mock, teamBackend)
+	mock.ExpectQuery(`SELECT team_name FROM users WHERE user_id=`).WithArgs("u3").WillReturnError(sql.ErrNoRows)
+	// u4 was removed from a team earlier and has none.
+	mock.ExpectQuery(`SELECT team_name FROM users WHERE user_id=`).WithArgs("u4").
+		WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow(nil))
+	mock.ExpectExec(`INSERT INTO users`).WithArgs("u3", "Carol", true, teamBackend, 1, 0, RoleMember).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO users`).WithArgs("u4", "Dan", true, teamBackend, 1, 0, RoleMember).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectBuildTeam(mock, teamBackend, "u1", "u3", "u4")
+	mock.ExpectCommit()
+
+	team, err := store.AddTeamMembers(context.Background(), MembersPayload{
+		TeamName: teamBackend,
+		Members: []TeamUpserted{
+			{UserID: "u3", Username: "Carol", IsActive: true},
+			{UserID: "u4", Username: "Dan", IsActive: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddTeamMembers error: %v", err)
+	}
+	if len(team.Members) != 3 {
+		t.Fatalf("unexpected members: %+v", team.Members)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestAddTeamMembersRejectsOtherTeam(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockTeam(mock, teamBackend)
+	mock.ExpectQuery(`SELECT team_name FROM users WHERE user_id=`).WithArgs("u9").
+		WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow("platform"))
+	mock.ExpectRollback()
+
+	_, err := store.AddTeamMembers(context.Background(), MembersPayload{
+		TeamName: teamBackend,
+		Members:  []TeamUpserted{{UserID: "u9", Username: "Zed", IsActive: true}},
+	})
+	if !errors.Is(err, ErrMemberOfOtherTeam) || !strings.Contains(err.Error(), "platform") {
+		t.Fatalf("expected ErrMemberOfOtherTeam, got %v", err)
+	}
+}
+
+func TestAddTeamMembersUnknownTeam(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT name FROM teams WHERE name=\$1 FOR UPDATE`).WithArgs("ghost").WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err := store.AddTeamMembers(context.Background(), MembersPayload{
+		TeamName: "ghost",
+		Members:  []TeamUpserted{{UserID: "u1"}},
+	})
+	if !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+}
+
+func expectAuthoredPending(mock sqlmock.Sqlmock, userID string, prIDs ...string) {
+	rows := sqlmock.NewRows([]string{"pr_id"})
+	for _, id := range prIDs {
+		rows.AddRow(id)
+	}
+	mock.ExpectQuery(`SELECT pr_id\s+FROM pull_requests\s+WHERE author_id=\$1 AND status IN`).
+		WithArgs(userID, StatusDraft, StatusOpen).WillReturnRows(rows)
+}
+
+func TestRemoveTeamMembers(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockTeam(mock, teamBackend)
+	expectLockUser(mock, "u2", teamBackend)
+	expectAuthoredPending(mock, "u2")
+	mock.ExpectQuery(`SELECT pr.pr_id, pr.author_id, ar.user_id`).WithArgs(StatusOpen, "u2").
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "author_id", "user_id"}).AddRow("pr1", authorID, "u2"))
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers WHERE pr_id=`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u2"))
+	expectDeactivationPick(mock, "pr1", teamBackend, "u3")
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "u2").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "u2", EventUnassigned, ReasonUserRemoved)
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "u3").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "u3", EventAssigned, ReasonUserRemoved)
+	mock.ExpectQuery(`UPDATE users\s+SET team_name = NULL`).WithArgs("u2").
+		WillReturnRows(sqlmock.NewRows(userRowColumns).AddRow("u2", "Bob", nil, true, 0, RoleMember))
+	expectBuildTeam(mock, teamBackend, "u1", "u3")
+	mock.ExpectCommit()
+
+	removed, err := store.RemoveTeamMembers(context.Background(), RemoveMembersPayload{
+		TeamName: teamBackend, UserIDs: []string{"u2", "u2"},
+	})
+	if err != nil {
+		t.Fatalf("RemoveTeamMembers error: %v", err)
+	}
+	if len(removed.Removed) != 1 || removed.Removed[0].User.TeamName != "" || len(removed.Team.Members) != 2 {
+		t.Fatalf("unexpected result: %+v", removed)
+	}
+	want := []Reassignment{{PRID: "pr1", OldUserID: "u2", NewUserID: "u3"}}
+	if summary := removed.Removed[0].Reassignment; summary == nil || !reflect.DeepEqual(summary.Reassigned, want) {
+		t.Fatalf("unexpected reassignment: %+v", summary)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRemoveTeamMembersRefusesAuthors(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockTeam(mock, teamBackend)
+	expectLockUser(mock, "u2", teamBackend)
+	expectAuthoredPending(mock, "u2", "pr1", "pr2")
+	mock.ExpectRollback()
+
+	_, err := store.RemoveTeamMembers(context.Background(), RemoveMembersPayload{
+		TeamName: teamBackend, UserIDs: []string{"u2"},
+	})
+	if !errors.Is(err, ErrMemberHasOpenPRs) || !strings.Contains(err.Error(), "pr1, pr2") {
+		t.Fatalf("expected ErrMemberHasOpenPRs, got %v", err)
+	}
+}
+
+func TestRemoveTeamMembersRejectsOtherTeam(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockTeam(mock, teamBackend)
+	expectLockUser(mock, "u9", "platform")
+	mock.ExpectRollback()
+
+	_, err := store.RemoveTeamMembers(context.Background(), RemoveMembersPayload{
+		TeamName: teamBackend, UserIDs: []string{"u9"},
+	})
+	if !errors.Is(err, ErrNotTeamMember) {
+		t.Fatalf("expected ErrNotTeamMember, got %v", err)
+	}
+}
+
+func TestRenameTeam(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE teams SET name = \$2 WHERE name = \$1`).WithArgs(teamBackend, "core").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectBuildTeam(mock, "core", "u1")
+	mock.ExpectCommit()
+
+	team, err := store.RenameTeam(context.Background(), RenameTeamPayload{TeamName: teamBackend, NewName: "core"})
+	if err != nil {
+		t.Fatalf("RenameTeam error: %v", err)
+	}
+	if team.TeamName != "core" {
+		t.Fatalf("unexpected team: %+v", team)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRenameTeamErrors(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE teams SET name`).WithArgs("ghost", "core").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE teams SET name`).WithArgs(teamBackend, "platform").
+		WillReturnError(errors.New("duplicate key value"))
+	mock.ExpectRollback()
+
+	if _, err := store.RenameTeam(context.Background(), RenameTeamPayload{TeamName: "ghost", NewName: "core"}); !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+	if _, err := store.RenameTeam(context.Background(), RenameTeamPayload{TeamName: teamBackend, NewName: "platform"}); !errors.Is(err, ErrTeamExists) {
+		t.Fatalf("expected ErrTeamExists, got %v", err)
+	}
+}
+
+func TestDeleteTeam(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockTeam(mock, teamBackend)
+	mock.ExpectQuery(`SELECT user_id FROM users WHERE team_name=\$1`).WithArgs(teamBackend).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectQuery(`SELECT r.team_name, r.pattern`).WithArgs(teamBackend).
+		WillReturnRows(sqlmock.NewRows([]string{"team_name", "pattern", "orphaned"}).
+			AddRow(teamBackend, "*.go", false).
+			AddRow("platform", "/api/", true))
+	mock.ExpectExec(`DELETE FROM teams WHERE name=`).WithArgs(teamBackend).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	deleted, err := store.DeleteTeam(context.Background(), teamBackend)
+	if err != nil {
+		t.Fatalf("DeleteTeam error: %v", err)
+	}
+	want := []OwnedRule{{TeamName: "platform", Pattern: "/api/", Orphaned: true}}
+	if !reflect.DeepEqual(deleted.OwnershipRules, want) {
+		t.Fatalf("unexpected result: %+v", deleted)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestDeleteTeamRefusesWithMembers(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockTeam(mock, teamBackend)
+	mock.ExpectQuery(`SELECT user_id FROM users WHERE team_name=\$1`).WithArgs(teamBackend).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u1").AddRow("u2"))
+	mock.ExpectRollback()
+
+	_, err := store.DeleteTeam(context.Background(), teamBackend)
+	if !errors.Is(err, ErrTeamNotEmpty) || !strings.Contains(err.Error(), "u1, u2") {
+		t.Fatalf("expected ErrTeamNotEmpty, got %v", err)
+	}
+}
+
+func TestCreatePRAuthorWithoutTeam(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM pull_requests WHERE pr_id=`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`SELECT user_id, team_name FROM users WHERE user_id=`).WithArgs("u2").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_name"}).AddRow("u2", nil))
+	mock.ExpectRollback()
+
+	_, err := store.CreatePR(context.Background(), CreatePRPayload{ID: "pr1", Author: "u2"})
+	if !errors.Is(err, ErrTeamNotFound) || !strings.Contains(err.Error(), "has no team") {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+}
+
+func TestListTeams(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`SELECT t.name,\s+COUNT\(u.user_id\)`).WithArgs(StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "members", "active_members", "open_reviews"}).
+			AddRow(teamBackend, 3, 2, 4).
+			AddRow("empty", 0, 0, 0))
+
+	teams, err := store.ListTeams(context.Background())
+	if err != nil {
+		t.Fatalf("ListTeams error: %v", err)
+	}
+	want := []TeamSummary{
+		{TeamName: teamBackend, Members: 3, ActiveMembers: 2, OpenReviews: 4},
+		{TeamName: "empty"},
+	}
+	if !reflect.DeepEqual(teams, want) {
+		t.Fatalf("teams = %+v, want %+v", teams, want)
+	}
+}
@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type UpdateUserPayload struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// MoveTeamPayload moves a user to another team. Open reviews are handed over
+// to the old team unless KeepReviews is set.
+type MoveTeamPayload struct {
+	UserID      string `json:"user_id"`
+	TeamName    string `json:"team_name"`
+	KeepReviews bool   `json:"keep_reviews,omitempty"`
+}
+
+// DeletedUser reports what deleting a user changed besides the user itself.
+type DeletedUser struct {
+	UserID       string               `json:"user_id"`
+	Reassignment *ReassignmentSummary `json:"reassignment"`
+	// ClosedPRs are the draft and open PRs of the user, closed because nobody
+	// else can move them forward.
+	ClosedPRs []string `json:"closed_prs"`
+	// OwnershipRules lost the user as an owner.
+	OwnershipRules []OwnedRule `json:"ownership_rules"`
+}
+
+// OwnedRule is an ownership rule of a deleted user. An orphaned rule has no
+// owners left, so it now clears ownership of its paths.
+type OwnedRule struct {
+	TeamName string `json:"team_name"`
+	Pattern  string `json:"pattern"`
+	Orphaned bool   `json:"orphaned"`
+}
+
+const userColumns = `user_id, username, team_name, is_active, COALESCE(max_open_reviews, 0), role`
+
+func scanUser(row *sql.Row) (*User, error) {
+	var u User
+	var team sql.NullString
+	if err := row.Scan(&u.ID, &u.Username, &team, &u.IsActive, &u.MaxOpenReviews, &u.Role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	u.TeamName = team.String
+	return &u, nil
+}
+
+func (s *Store) GetUser(ctx context.Context, userID string) (*User, error) {
+	return scanUser(s.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE user_id=$1`, userID))
+}
+
+// UpdateUser renames a user; team membership has its own endpoint.
+func (s *Store) UpdateUser(ctx context.Context, payload UpdateUserPayload) (*User, error) {
+	return scanUser(s.db.QueryRowContext(ctx, `
+UPDATE users
+SET username = $2
+WHERE user_id = $1
+RETURNING `+userColumns, payload.UserID, payload.Username))
+}
+
+// MoveUserTeam moves a user to another team. Their open reviews belong to PRs
+// of the old team, so by default each is reassigned within the old team the
+// same way deactivation does; authored PRs keep their reviewers.
+func (s *Store) MoveUserTeam(ctx context.Context, payload MoveTeamPayload) (*User, *ReassignmentSummary, error) {
+	for attempts := 0; attempts < 3; attempts++ {
+		u, summary, err := s.moveUserTeamOnce(ctx, payload)
+		if err == nil {
+			return u, summary, nil
+		}
+		if isSerializationError(err) && attempts < 2 {
+			time.Sleep(time.Duration(attempts+1) * 10 * time.Millisecond)
+			continue
+		}
+		return nil, nil, err
+	}
+	return nil, nil, fmt.Errorf("unreachable")
+}
+
+func (s *Store) moveUserTeamOnce(ctx context.Context, payload MoveTeamPayload) (*User, *ReassignmentSummary, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	oldTeam, err := lockUser(ctx, tx, payload.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var exists bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM teams WHERE name=$1)`, payload.TeamName).Scan(&exists); err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, ErrTeamNotFound
+	}
+	var summary *ReassignmentSummary
+	if !payload.KeepReviews && oldTeam != payload.TeamName {
+		assignments, err := s.fetchUserAssignments(ctx, tx, payload.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if summary, err = s.reassignAfterDeactivation(ctx, tx, oldTeam, assignments, ReasonUserMoved); err != nil {
+			return nil, nil, err
+		}
+	}
+	u, err := scanUser(tx.QueryRowContext(ctx, `
+UPDATE users
+SET team_name = $2
+WHERE user_id = $1
+RETURNING `+userColumns, payload.UserID, payload.TeamName))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return u, summary, nil
+}
+
+// DeleteUser removes a user. Their open reviews are reassigned within their
+// team, their draft and open PRs are closed, and PRs they authored stay with
+// an empty author_id. Reviews and verdicts on merged PRs and the assignment
+// events keep the user id; the user is dropped from ownership rules.
+func (s *Store) DeleteUser(ctx context.Context, userID string) (*DeletedUser, error) {
+	for attempts := 0; attempts < 3; attempts++ {
+		deleted, err := s.deleteUserOnce(ctx, userID)
+		if err == nil {
+			return deleted, nil
+		}
+		if isSerializationError(err) && attempts < 2 {
+			time.Sleep(time.Duration(attempts+1) * 10 * time.Millisecond)
+			continue
+		}
+		return nil, err
+	}
+	return nil, fmt.Errorf("unreachable")
+}
+
+func (s *Store) deleteUserOnce(ctx context.Context, userID string) (*DeletedUser, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warnf("rollback failed: %v", err)
+		}
+	}()
+
+	teamName, err := lockUser(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := s.fetchUserAssignments(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := s.reassignAfterDeactivation(ctx, tx, teamName, assignments, ReasonUserDeleted)
+	if err != nil {
+		return nil, err
+	}
+	closed, err := authoredPendingPRs(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, prID := range closed {
+		if err := s.closePRTx(ctx, tx, prID, ReasonAuthorDeleted); err != nil {
+			return nil, err
+		}
+	}
+	owned, err := ownedRules(ctx, tx, "user_id", userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE user_id=$1`, userID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &DeletedUser{UserID: userID, Reassignment: summary, ClosedPRs: closed, OwnershipRules: owned}, nil
+}
+
+// lockUser locks the user row and returns the user's team, empty for a user
+// removed from their team.
+func lockUser(ctx context.Context, tx *sql.Tx, userID string) (string, error) {
+	var teamName sql.NullString
+	if err := tx.QueryRowContext(ctx,
+		`SELECT team_name FROM users WHERE user_id=$1 FOR UPDATE`, userID).Scan(&teamName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrUserNotFound
+		}
+		return "", err
+	}
+	return teamName.String, nil
+}
+
+// ownedRules returns the ownership rules naming an owner and whether any
+// other owner is left on each. column is the owner column of
+// ownership_owners: user_id or owner_team.
+func ownedRules(ctx context.Context, tx *sql.Tx, column, owner string) ([]OwnedRule, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT r.team_name, r.pattern,
+       NOT EXISTS(SELECT 1 FROM ownership_owners x
+                  WHERE x.team_name = o.team_name AND x.position = o.position
+                    AND x.`+column+` IS DISTINCT FROM $1)
+FROM ownership_owners o
+JOIN ownership_rules r ON r.team_name = o.team_name AND r.position = o.position
+WHERE o.`+column+` = $1
+ORDER BY r.team_name, r.position
+`, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	out := make([]OwnedRule, 0)
+	for rows.Next() {
+		var rule OwnedRule
+		if err := rows.Scan(&rule.TeamName, &rule.Pattern, &rule.Orphaned); err != nil {
+			return nil, err
+		}
+		out = append(out, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// authoredPendingPRs locks and returns the draft and open PRs of an author.
+func authoredPendingPRs(ctx context.Context, tx *sql.Tx, authorID string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+SELECT pr_id
+FROM pull_requests
+WHERE author_id=$1 AND status IN ($2, $3)
+ORDER BY pr_id
+FOR UPDATE
+`, authorID, StatusDraft, StatusOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var userRowColumns = []string{"user_id", "username", "team_name", "is_active", "max_open_reviews", "role"}
+
+func expectLockUser(mock sqlmock.Sqlmock, userID, team string) {
+	mock.ExpectQuery(`SELECT team_name FROM users WHERE user_id=\$1 FOR UPDATE`).WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"team_name"}).AddRow(team))
+}
+
+func TestGetUser(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`SELECT user_id, username, team_name, is_active, COALESCE\(max_open_reviews, 0\), role FROM users`).
+		WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows(userRowColumns).AddRow("u1", "Alice", teamBackend, true, 2, RoleSenior))
+	mock.ExpectQuery(`SELECT user_id, username, team_name`).WithArgs("u404").WillReturnError(sql.ErrNoRows)
+
+	u, err := store.GetUser(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("GetUser error: %v", err)
+	}
+	want := &User{ID: "u1", Username: "Alice", TeamName: teamBackend, IsActive: true, MaxOpenReviews: 2, Role: RoleSenior}
+	if !reflect.DeepEqual(u, want) {
+		t.Fatalf("user = %+v, want %+v", u, want)
+	}
+	if _, err := store.GetUser(context.Background(), "u404"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectQuery(`UPDATE users\s+SET username = \$2`).WithArgs("u1", "Alicia").
+		WillReturnRows(sqlmock.NewRows(userRowColumns).AddRow("u1", "Alicia", teamBackend, true, 0, RoleMember))
+
+	u, err := store.UpdateUser(context.Background(), UpdateUserPayload{UserID: "u1", Username: "Alicia"})
+	if err != nil {
+		t.Fatalf("UpdateUser error: %v", err)
+	}
+	if u.Username != "Alicia" {
+		t.Fatalf("unexpected user: %+v", u)
+	}
+}
+
+func TestMoveUserTeamReassignsOpenReviews(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockUser(mock, "u1", teamBackend)
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).WithArgs("platform").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT pr.pr_id, pr.author_id, ar.user_id`).WithArgs(StatusOpen, "u1").
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "author_id", "user_id"}).AddRow("pr1", authorID, "u1"))
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers WHERE pr_id=`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u1"))
+	expectDeactivationPick(mock, "pr1", teamBackend, "u3")
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr1", "u1").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "u1", EventUnassigned, ReasonUserMoved)
+	mock.ExpectExec(`INSERT INTO assigned_reviewers`).WithArgs("pr1", "u3").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr1", "u3", EventAssigned, ReasonUserMoved)
+	mock.ExpectQuery(`UPDATE users\s+SET team_name = \$2`).WithArgs("u1", "platform").
+		WillReturnRows(sqlmock.NewRows(userRowColumns).AddRow("u1", "Alice", "platform", true, 0, RoleMember))
+	mock.ExpectCommit()
+
+	u, summary, err := store.MoveUserTeam(context.Background(), MoveTeamPayload{UserID: "u1", TeamName: "platform"})
+	if err != nil {
+		t.Fatalf("MoveUserTeam error: %v", err)
+	}
+	if u.TeamName != "platform" {
+		t.Fatalf("unexpected user: %+v", u)
+	}
+	want := []Reassignment{{PRID: "pr1", OldUserID: "u1", NewUserID: "u3"}}
+	if summary == nil || !reflect.DeepEqual(summary.Reassigned, want) {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMoveUserTeamKeepsReviews(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockUser(mock, "u1", teamBackend)
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).WithArgs("platform").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`UPDATE users\s+SET team_name = \$2`).WithArgs("u1", "platform").
+		WillReturnRows(sqlmock.NewRows(userRowColumns).AddRow("u1", "Alice", "platform", true, 0, RoleMember))
+	mock.ExpectCommit()
+
+	_, summary, err := store.MoveUserTeam(context.Background(), MoveTeamPayload{UserID: "u1", TeamName: "platform", KeepReviews: true})
+	if err != nil {
+		t.Fatalf("MoveUserTeam error: %v", err)
+	}
+	if summary != nil {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMoveUserTeamNotFound(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockUser(mock, "u1", teamBackend)
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM teams WHERE name=`).WithArgs("ghost").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+
+	if _, _, err := store.MoveUserTeam(context.Background(), MoveTeamPayload{UserID: "u1", TeamName: "ghost"}); !errors.Is(err, ErrTeamNotFound) {
+		t.Fatalf("expected ErrTeamNotFound, got %v", err)
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockUser(mock, "u1", teamBackend)
+	mock.ExpectQuery(`SELECT pr.pr_id, pr.author_id, ar.user_id`).WithArgs(StatusOpen, "u1").
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "author_id", "user_id"}))
+	mock.ExpectQuery(`SELECT pr_id\s+FROM pull_requests\s+WHERE author_id=\$1 AND status IN`).
+		WithArgs("u1", StatusDraft, StatusOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id"}).AddRow("pr7"))
+	mock.ExpectQuery(`SELECT user_id FROM assigned_reviewers WHERE pr_id=`).WithArgs("pr7").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u2"))
+	mock.ExpectExec(`DELETE FROM assigned_reviewers`).WithArgs("pr7", "u2").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, "pr7", "u2", EventUnassigned, ReasonAuthorDeleted)
+	mock.ExpectExec(`UPDATE pull_requests SET status`).WithArgs("pr7", StatusClosed).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT r.team_name, r.pattern`).WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"team_name", "pattern", "orphaned"}).
+			AddRow(teamBackend, "/api/", false).
+			AddRow(teamBackend, "*.sql", true))
+	mock.ExpectExec(`DELETE FROM users WHERE user_id=`).WithArgs("u1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	deleted, err := store.DeleteUser(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("DeleteUser error: %v", err)
+	}
+	if !reflect.DeepEqual(deleted.ClosedPRs, []string{"pr7"}) || len(deleted.Reassignment.Reassigned) != 0 {
+		t.Fatalf("unexpected result: %+v", deleted)
+	}
+	wantRules := []OwnedRule{
+		{TeamName: teamBackend, Pattern: "/api/"},
+		{TeamName: teamBackend, Pattern: "*.sql", Orphaned: true},
+	}
+	if !reflect.DeepEqual(deleted.OwnershipRules, wantRules) {
+		t.Fatalf("ownership rules = %+v, want %+v", deleted.OwnershipRules, wantRules)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestDeleteUserNotFound(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT team_name FROM users WHERE user_id=\$1 FOR UPDATE`).WithArgs("u404").WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	if _, err := store.DeleteUser(context.Background(), "u404"); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
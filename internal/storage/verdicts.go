@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Verdicts a reviewer may submit on an open PR.
+const (
+	VerdictApproved         = "APPROVED"
+	VerdictChangesRequested = "CHANGES_REQUESTED"
+	VerdictCommented        = "COMMENTED"
+)
+
+var verdicts = []string{VerdictApproved, VerdictChangesRequested, VerdictCommented}
+
+// Review is the latest verdict of one reviewer.
+type Review struct {
+	UserID      string    `json:"user_id"`
+	Verdict     string    `json:"verdict"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+type SubmitReviewPayload struct {
+	PRID    string `json:"pull_request_id"`
+	UserID  string `json:"user_id"`
+	Verdict string `json:"verdict"`
+}
+
+// SubmitReview records the verdict of an active assigned reviewer of an open
+// PR. A later verdict replaces the earlier one; a reviewer that is reassigned
+// or removed takes their verdict with them. Closing a PR unassigns all its
+// reviewers, so a reopened PR starts without approvals.
+func (s *Store) SubmitReview(ctx context.Context, payload SubmitReviewPayload) (*PullRequest, error) {
+	if !slices.Contains(verdicts, payload.Verdict) {
+		return nil, ErrInvalidVerdict
+	}
+	return s.changeReviewers(ctx, ReviewerPayload{PRID: payload.PRID, UserID: payload.UserID},
+		func(ctx context.Context, tx *sql.Tx, reviewer ReviewerPayload) error {
+			return s.submitReviewOnce(ctx, tx, reviewer, payload.Verdict)
+		})
+}
+
+func (s *Store) submitReviewOnce(ctx context.Context, tx *sql.Tx, payload ReviewerPayload, verdict string) error {
+	prMeta, err := s.loadPRMeta(ctx, tx, payload.PRID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(prMeta.currentReviewers, payload.UserID) {
+		return ErrNotAssigned
+	}
+	var active bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT is_active FROM users WHERE user_id=$1`, payload.UserID).Scan(&active); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if !active {
+		return fmt.Errorf("%w: %q is inactive", ErrIneligibleReviewer, payload.UserID)
+	}
+	_, err = tx.ExecContext(ctx, `
+UPDATE assigned_reviewers
+SET verdict = $3, verdict_at = NOW()
+WHERE pr_id = $1 AND user_id = $2
+`, payload.PRID, payload.UserID, verdict)
+	return err
+}
+
+// checkApprovals enforces the required_approvals setting of the author's team
+// on an open PR about to be merged.
+func checkApprovals(ctx context.Context, tx *sql.Tx, prID string) error {
+	var required, approvals int
+	if err := tx.QueryRowContext(ctx, `
+SELECT COALESCE(t.required_approvals, 0),
+       (SELECT COUNT(*) FROM assigned_reviewers ar WHERE ar.pr_id = pr.pr_id AND ar.verdict = $2)
+FROM pull_requests pr
+JOIN users a ON a.user_id = pr.author_id
+JOIN teams t ON t.name = a.team_name
+WHERE pr.pr_id=$1
+`, prID, VerdictApproved).Scan(&required, &approvals); err != nil {
+		// Deleting a user closes their PRs and members with open PRs cannot
+		// leave their team, so this is not expected; without the author's
+		// team the setting is unknown and the merge is refused.
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: the author of %s was deleted or has no team", ErrInvalidStatus, prID)
+		}
+		return err
+	}
+	if approvals < required {
+		return fmt.Errorf("%w: %d of %d required approvals", ErrNotEnoughApprovals, approvals, required)
+	}
+	return nil
+}
+
+// listReviews returns the reviewers of a PR and the verdicts they submitted.
+func listReviews(ctx context.Context, q querier, prID string) ([]string, []Review, error) {
+	rows, err := q.QueryContext(ctx, `
+SELECT user_id, verdict, verdict_at
+FROM assigned_reviewers
+WHERE pr_id=$1
+ORDER BY user_id
+`, prID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var reviewers []string
+	var reviews []Review
+	for rows.Next() {
+		var id string
+		var verdict sql.NullString
+		var at sql.NullTime
+		if err := rows.Scan(&id, &verdict, &at); err != nil {
+			return nil, nil, err
+		}
+		reviewers = append(reviewers, id)
+		if verdict.Valid {
+			reviews = append(reviews, Review{UserID: id, Verdict: verdict.String, SubmittedAt: at.Time})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return reviewers, reviews, nil
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const approvalsPattern = `SELECT COALESCE\(t.required_approvals, 0\)`
+
+func expectReviewerActive(mock sqlmock.Sqlmock, userID string, active bool) {
+	mock.ExpectQuery(`SELECT is_active FROM users WHERE user_id=`).WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"is_active"}).AddRow(active))
+}
+
+func TestSubmitReview(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	submittedAt := time.Now()
+	mock.ExpectBegin()
+	expectPRMeta(mock, StatusOpen, "u1", "u2")
+	expectReviewerActive(mock, "u1", true)
+	mock.ExpectExec(`UPDATE assigned_reviewers\s+SET verdict = \$3`).WithArgs("pr1", "u1", VerdictApproved).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT pr_id, pr_name, author_id, status, created_at, merged_at FROM pull_requests`).
+		WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"pr_id", "pr_name", "author_id", "status", "created_at", "merged_at"}).
+			AddRow("pr1", "feature", "author", StatusOpen, time.Now(), nil))
+	mock.ExpectQuery(reviewsPattern).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "verdict", "verdict_at"}).
+			AddRow("u1", VerdictApproved, submittedAt).
+			AddRow("u2", nil, nil))
+	mock.ExpectCommit()
+
+	pr, err := store.SubmitReview(context.Background(), SubmitReviewPayload{PRID: "pr1", UserID: "u1", Verdict: VerdictApproved})
+	if err != nil {
+		t.Fatalf("SubmitReview error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 2 || len(pr.Reviews) != 1 {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+	if r := pr.Reviews[0]; r.UserID != "u1" || r.Verdict != VerdictApproved || !r.SubmittedAt.Equal(submittedAt) {
+		t.Fatalf("unexpected review: %+v", r)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSubmitReviewValidation(t *testing.T) {
+	t.Run("unknown verdict", func(t *testing.T) {
+		store, _, cleanup := newMockStore(t)
+		defer cleanup()
+
+		_, err := store.SubmitReview(context.Background(), SubmitReviewPayload{PRID: "pr1", UserID: "u1", Verdict: "LGTM"})
+		if !errors.Is(err, ErrInvalidVerdict) {
+			t.Fatalf("expected ErrInvalidVerdict, got %v", err)
+		}
+	})
+	t.Run("not assigned", func(t *testing.T) {
+		store, mock, cleanup := newMockStore(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		expectPRMeta(mock, StatusOpen, "u1", "u2")
+		mock.ExpectRollback()
+
+		_, err := store.SubmitReview(context.Background(), SubmitReviewPayload{PRID: "pr1", UserID: "u3", Verdict: VerdictCommented})
+		if !errors.Is(err, ErrNotAssigned) {
+			t.Fatalf("expected ErrNotAssigned, got %v", err)
+		}
+	})
+	t.Run("inactive", func(t *testing.T) {
+		store, mock, cleanup := newMockStore(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		expectPRMeta(mock, StatusOpen, "u1", "u2")
+		expectReviewerActive(mock, "u1", false)
+		mock.ExpectRollback()
+
+		_, err := store.SubmitReview(context.Background(), SubmitReviewPayload{PRID: "pr1", UserID: "u1", Verdict: VerdictApproved})
+		if !errors.Is(err, ErrIneligibleReviewer) {
+			t.Fatalf("expected ErrIneligibleReviewer, got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("expectations: %v", err)
+		}
+	})
+	t.Run("merged", func(t *testing.T) {
+		store, mock, cleanup := newMockStore(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		expectPRMeta(mock, StatusMerged)
+		mock.ExpectRollback()
+
+		_, err := store.SubmitReview(context.Background(), SubmitReviewPayload{PRID: "pr1", UserID: "u1", Verdict: VerdictApproved})
+		if !errors.Is(err, ErrPRMerged) {
+			t.Fatalf("expected ErrPRMerged, got %v", err)
+		}
+	})
+}
+
+func TestMergePRRequiresApprovals(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockPR(mock, StatusOpen)
+	mock.ExpectQuery(approvalsPattern).WithArgs("pr1", VerdictApproved).
+		WillReturnRows(sqlmock.NewRows([]string{"required_approvals", "approvals"}).AddRow(2, 1))
+	mock.ExpectRollback()
+
+	_, err := store.MergePR(context.Background(), "pr1")
+	if !errors.Is(err, ErrNotEnoughApprovals) {
+		t.Fatalf("expected ErrNotEnoughApprovals, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMergePRWithApprovals(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	expectLockPR(mock, StatusOpen)
+	mock.ExpectQuery(approvalsPattern).WithArgs("pr1", VerdictApproved).
+		WillReturnRows(sqlmock.NewRows([]string{"required_approvals", "approvals"}).AddRow(1, 1))
+	mock.ExpectExec(`UPDATE pull_requests`).WithArgs("pr1", StatusMerged).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectFetchPRStatus(mock, StatusMerged, "u1")
+	mock.ExpectCommit()
+
+	pr, err := store.MergePR(context.Background(), "pr1")
+	if err != nil {
+		t.Fatalf("MergePR error: %v", err)
+	}
+	if pr.Status != StatusMerged {
+		t.Fatalf("unexpected pr: %+v", pr)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMergePRDeletedAuthor(t *testing.T) {
+	store, mock, cleanup := newMockStore(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT author_id, status FROM pull_requests WHERE pr_id=\$1 FOR UPDATE`).WithArgs("pr1").
+		WillReturnRows(sqlmock.NewRows([]string{"author_id", "status"}).AddRow(nil, StatusOpen))
+	mock.ExpectQuery(approvalsPattern).WithArgs("pr1", VerdictApproved).WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	if _, err := store.MergePR(context.Background(), "pr1"); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected ErrInvalidStatus, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestUpdateTeamSettingsRejectsInvalidApprovals(t *testing.T) {
+	for _, approvals := range []int{-1, DefaultMaxReviewers + 1} {
+		store, mock, cleanup := newMockStore(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(teamSettingsPattern + `\s+WHERE name=\$1 FOR UPDATE`).
+			WithArgs(teamBackend).
+			WillReturnRows(teamSettingsRows(nil, DefaultMinReviewers, DefaultMaxReviewers))
+		mock.ExpectRollback()
+
+		_, err := store.UpdateTeamSettings(context.Background(), TeamSettingsPayload{
+			TeamName: teamBackend, RequiredApprovals: &approvals,
+		})
+		cleanup()
+		if !errors.Is(err, ErrInvalidApprovals) {
+			t.Fatalf("approvals %d: expected ErrInvalidApprovals, got %v", approvals, err)
+		}
+	}
+}